@@ -0,0 +1,82 @@
+package main
+
+import (
+	"patukek/internal/patukek_astjson"
+	"patukek/internal/patukek_parser"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func astCmd(args []string) int {
+	fs := flag.NewFlagSet("ast", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "print the tree as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek ast [-json] file.ptk")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	path := fs.Arg(0)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := printAST(os.Stdout, path, string(src), *jsonOut); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// printAST writes the tree parsed from src to w, as JSON or as an
+// indented outline.
+func printAST(w io.Writer, path, src string, jsonOut bool) error {
+	tree, errs := patukek_parser.Parse(path, src)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	f := patukek_astjson.New(path, src, tree)
+	if jsonOut {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(f)
+	}
+
+	printNode(w, f.Root, 0)
+	return nil
+}
+
+func printNode(w io.Writer, n *patukek_astjson.Node, depth int) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s%s %d:%d-%d:%d", strings.Repeat("  ", depth), n.Kind,
+		n.Span.Start.Line, n.Span.Start.Column, n.Span.End.Line, n.Span.End.Column)
+	if n.Name != "" {
+		fmt.Fprintf(&b, " %s", n.Name)
+	}
+	if n.Op != "" {
+		fmt.Fprintf(&b, " %s", n.Op)
+	}
+	if n.Value != nil {
+		fmt.Fprintf(&b, " %#v", n.Value)
+	}
+	fmt.Fprintln(w, b.String())
+
+	for _, c := range n.Children {
+		printNode(w, c, depth+1)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"patukek/internal/patukek_fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestASTJSON compares the output of patukek ast -json with
+// testdata/ast.json. Editors and other tools parse it, so a change to it
+// must come with a new schema version when it renames or removes a field.
+func TestASTJSON(t *testing.T) {
+	const path, golden = "testdata/ast.ptk", "testdata/ast.json"
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got strings.Builder
+	if err := printAST(&got, path, string(src), true); err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := os.WriteFile(golden, []byte(got.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("output differs from %s:\n%s", golden, patukek_fmt.Diff(golden, want, []byte(got.String())))
+	}
+}
+
+func TestASTErrors(t *testing.T) {
+	var out strings.Builder
+	if err := printAST(&out, "test.ptk", "x = (", true); err == nil {
+		t.Errorf("printAST of a file with syntax errors succeeded: %s", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"patukek/internal/patukek_parser"
+	"patukek/internal/patukek_types"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func checkCmd(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	infer := fs.Bool("infer", false, "infer the type of every value strictly, and print the types of the top-level variables")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek check [-infer] file.ptk ...")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var ret int
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ret = 1
+			continue
+		}
+
+		var sigs []patukek_types.Signature
+		tree, errs := patukek_parser.Parse(path, string(src))
+		switch {
+		case len(errs) > 0:
+		case *infer:
+			sigs, errs = patukek_types.Infer(path, string(src), tree)
+		default:
+			errs = patukek_types.Check(path, string(src), tree)
+		}
+
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+		if len(errs) > 0 {
+			ret = 1
+			continue
+		}
+
+		if len(sigs) > 0 && fs.NArg() > 1 {
+			fmt.Printf("%s:\n", path)
+		}
+		for _, s := range sigs {
+			fmt.Println(s)
+		}
+	}
+	return ret
+}
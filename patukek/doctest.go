@@ -0,0 +1,55 @@
+package main
+
+import (
+	"patukek/internal/patukek_doctest"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func doctestCmd(args []string) int {
+	fs := flag.NewFlagSet("doctest", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "list every example, not only the failing ones")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek doctest [-v] file.md ...")
+		fmt.Fprintln(fs.Output(), "Runs the example programs of Markdown files and compares their output.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var passed, failed int
+	for _, path := range fs.Args() {
+		doc, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+			continue
+		}
+
+		for _, e := range patukek_doctest.Extract(path, string(doc)) {
+			d := e.Check()
+			if d == nil {
+				passed++
+				if *verbose {
+					fmt.Printf("ok   %s (%s:%d)\n", e.Name, path, e.Line)
+				}
+				continue
+			}
+
+			failed++
+			fmt.Printf("FAIL %s (%s:%d)\n", e.Name, path, e.Line)
+			os.Stdout.Write(d)
+		}
+	}
+
+	fmt.Printf("%d passed, %d failed\n", passed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"patukek/internal/patukek_doctest"
+	"patukek/internal/patukek_fmt"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the examples and testdata")
+
+// TestExamples runs every program of examples/ and compares what it
+// prints with the .out file next to it. Test suites (*_test.ptk) are
+// left to "patukek test".
+func TestExamples(t *testing.T) {
+	files, err := filepath.Glob("examples/*.ptk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.ptk") {
+			continue
+		}
+
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := patukek_doctest.Run(path, string(src))
+			golden := strings.TrimSuffix(path, ".ptk") + ".out"
+
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("output of %s differs from %s:\n%s", path, golden, patukek_fmt.Diff(golden, want, []byte(got)))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"patukek/internal/patukek_fmt"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func fmtCmd(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := fs.Bool("w", false, "write the result to the source file instead of stdout")
+	diff := fs.Bool("d", false, "display diffs instead of rewriting files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek fmt [-w | -d] [file.ptk ...]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return formatFile("<stdin>", src, false, *diff)
+	}
+
+	var ret int
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ret = 1
+			continue
+		}
+		if code := formatFile(path, src, *write, *diff); code != 0 {
+			ret = code
+		}
+	}
+	return ret
+}
+
+func formatFile(path string, src []byte, write, diff bool) int {
+	out, err := patukek_fmt.Format(path, string(src))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch {
+	case diff:
+		os.Stdout.Write(patukek_fmt.Diff(path, src, out))
+
+	case write:
+		if bytes.Equal(src, out) {
+			return 0
+		}
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+	default:
+		os.Stdout.Write(out)
+	}
+	return 0
+}
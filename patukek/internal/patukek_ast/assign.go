@@ -5,12 +5,14 @@ import (
 
 	"patukek/internal/patukek_code"
 	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_obj"
 )
 
 type Assign struct {
 	l   Node
 	r   Node
 	pos int
+	Doc string
 }
 
 func NewAssign(l, r Node, pos int) Node {
@@ -25,25 +27,56 @@ func (a Assign) String() string {
 	return fmt.Sprintf("(%v = %v)", a.l, a.r)
 }
 
-func (a Assign) Compile(c *patukek_compiler.Compiler) (p int, err error) {
-	defer c.Bookmark(p)
+func (a Assign) Pos() int {
+	return a.l.Pos()
+}
+
+func (a Assign) End() int {
+	return a.r.End()
+}
+
+func (a Assign) Children() []Node {
+	return []Node{a.l, a.r}
+}
+
+func (a Assign) Left() Node {
+	return a.l
+}
+
+func (a Assign) Right() Node {
+	return a.r
+}
 
+func (a Assign) Compile(c *patukek_compiler.Compiler) (p int, err error) {
 	switch left := a.l.(type) {
 	case Identifier:
-		symbol := c.Define(left.String())
+		symbol := c.DefineAt(left.String(), left.pos)
+		c.AddReference(left.String(), left.pos, symbol)
 		if p, err = a.r.Compile(c); err != nil {
 			return
 		}
 
 		if symbol.Scope == patukek_compiler.GlobalScope {
 			p = c.Emit(patukek_code.OpSetGlobal, symbol.Index)
-			c.Bookmark(a.pos)
+			c.Bookmark(a.Pos(), a.End())
 			return
 		} else {
 			p = c.Emit(patukek_code.OpSetLocal, symbol.Index)
-			c.Bookmark(a.pos)
+			c.Bookmark(a.Pos(), a.End())
+			return
+		}
+
+	case Dot:
+		if p, err = left.l.Compile(c); err != nil {
 			return
 		}
+		if p, err = a.r.Compile(c); err != nil {
+			return
+		}
+
+		p = c.Emit(patukek_code.OpSetAttr, c.AddConstant(patukek_obj.NewString(left.name)))
+		c.Bookmark(a.Pos(), a.End())
+		return
 
 	default:
 		return 0, fmt.Errorf("cannot assign to literal")
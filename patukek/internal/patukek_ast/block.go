@@ -7,26 +7,53 @@ import (
 	"patukek/internal/patukek_compiler"
 )
 
-type Block []Node
+type Block struct {
+	Nodes []Node
+	pos   int
+	end   int
+}
 
-func NewBlock() Block {
-	return []Node{}
+// NewBlock returns an empty block starting at pos, the offset of the
+// opening brace for braced blocks.
+func NewBlock(pos int) Block {
+	return Block{
+		Nodes: []Node{},
+		pos:   pos,
+	}
 }
 
 func (b *Block) String() string {
 	var nodes []string
-	for _, n := range *b {
+	for _, n := range b.Nodes {
 		nodes = append(nodes, n.String())
 	}
 	return strings.Join(nodes, "; ")
 }
 
+func (b *Block) Pos() int {
+	return b.pos
+}
+
+func (b *Block) End() int {
+	return b.end
+}
+
+func (b *Block) Children() []Node {
+	return b.Nodes
+}
+
+// SetEnd records the offset following the closing brace, or the end of
+// the input for the top-level block.
+func (b *Block) SetEnd(end int) {
+	b.end = end
+}
+
 func (b *Block) Add(n Node) {
-	*b = append(*b, n)
+	b.Nodes = append(b.Nodes, n)
 }
 
 func (b *Block) Compile(c *patukek_compiler.Compiler) (p int, err error) {
-	for _, n := range *b {
+	for _, n := range b.Nodes {
 		if p, err = n.Compile(c); err != nil {
 			return
 		}
@@ -40,4 +67,4 @@ func (b *Block) Compile(c *patukek_compiler.Compiler) (p int, err error) {
 
 func (b *Block) IsConstExpression() bool {
 	return false
-}
\ No newline at end of file
+}
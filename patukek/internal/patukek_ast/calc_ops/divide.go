@@ -26,6 +26,26 @@ func (d Divide) String() string {
 	return fmt.Sprintf("(%v / %v)", d.l, d.r)
 }
 
+func (d Divide) Pos() int {
+	return d.l.Pos()
+}
+
+func (d Divide) End() int {
+	return d.r.End()
+}
+
+func (d Divide) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{d.l, d.r}
+}
+
+func (d Divide) Op() string {
+	return "/"
+}
+
+func (d Divide) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return d.l, d.r
+}
+
 func (d Divide) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = d.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (d Divide) Compile(c *patukek_compiler.Compiler) (position int, err error)
 		return
 	}
 	position = c.Emit(patukek_code.OpDiv)
-	c.Bookmark(d.pos)
+	c.Bookmark(d.Pos(), d.End())
 	return
 }
 
@@ -26,6 +26,26 @@ func (m Minus) String() string {
 	return fmt.Sprintf("(%v - %v)", m.l, m.r)
 }
 
+func (m Minus) Pos() int {
+	return m.l.Pos()
+}
+
+func (m Minus) End() int {
+	return m.r.End()
+}
+
+func (m Minus) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{m.l, m.r}
+}
+
+func (m Minus) Op() string {
+	return "-"
+}
+
+func (m Minus) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return m.l, m.r
+}
+
 func (m Minus) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 
 	if position, err = m.l.Compile(c); err != nil {
@@ -35,7 +55,7 @@ func (m Minus) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 		return
 	}
 	position = c.Emit(patukek_code.OpSub)
-	c.Bookmark(m.pos)
+	c.Bookmark(m.Pos(), m.End())
 	return
 }
 
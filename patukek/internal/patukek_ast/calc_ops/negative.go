@@ -0,0 +1,54 @@
+package calc_ops
+
+import (
+	"fmt"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_code"
+	"patukek/internal/patukek_compiler"
+)
+
+type Negative struct {
+	r   patukek_ast.Node
+	pos int
+}
+
+func NewNegative(r patukek_ast.Node, pos int) patukek_ast.Node {
+	return Negative{
+		r:   r,
+		pos: pos,
+	}
+}
+
+func (n Negative) String() string {
+	return fmt.Sprintf("(-%v)", n.r)
+}
+
+func (n Negative) Pos() int {
+	return n.pos
+}
+
+func (n Negative) End() int {
+	return n.r.End()
+}
+
+func (n Negative) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{n.r}
+}
+
+func (n Negative) Operand() patukek_ast.Node {
+	return n.r
+}
+
+func (n Negative) Compile(c *patukek_compiler.Compiler) (position int, err error) {
+	if position, err = n.r.Compile(c); err != nil {
+		return
+	}
+	position = c.Emit(patukek_code.OpMinus)
+	c.Bookmark(n.Pos(), n.End())
+	return
+}
+
+func (n Negative) IsConstExpression() bool {
+	return n.r.IsConstExpression()
+}
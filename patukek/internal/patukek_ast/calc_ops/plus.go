@@ -26,6 +26,26 @@ func (p Plus) String() string {
 	return fmt.Sprintf("(%v + %v)", p.l, p.r)
 }
 
+func (p Plus) Pos() int {
+	return p.l.Pos()
+}
+
+func (p Plus) End() int {
+	return p.r.End()
+}
+
+func (p Plus) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{p.l, p.r}
+}
+
+func (p Plus) Op() string {
+	return "+"
+}
+
+func (p Plus) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return p.l, p.r
+}
+
 func (p Plus) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = p.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (p Plus) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 		return
 	}
 	position = c.Emit(patukek_code.OpAdd)
-	c.Bookmark(p.pos)
+	c.Bookmark(p.Pos(), p.End())
 	return
 }
 
@@ -26,6 +26,26 @@ func (t Times) String() string {
 	return fmt.Sprintf("(%v * %v)", t.l, t.r)
 }
 
+func (t Times) Pos() int {
+	return t.l.Pos()
+}
+
+func (t Times) End() int {
+	return t.r.End()
+}
+
+func (t Times) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{t.l, t.r}
+}
+
+func (t Times) Op() string {
+	return "*"
+}
+
+func (t Times) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return t.l, t.r
+}
+
 func (t Times) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = t.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (t Times) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 		return
 	}
 	position = c.Emit(patukek_code.OpMul)
-	c.Bookmark(t.pos)
+	c.Bookmark(t.Pos(), t.End())
 	return
 }
 
@@ -12,13 +12,15 @@ type Call struct {
 	Fn   Node
 	Args []Node
 	pos  int
+	end  int
 }
 
-func NewCall(fn Node, args []Node, pos int) Node {
+func NewCall(fn Node, args []Node, pos, end int) Node {
 	return Call{
 		Fn:   fn,
 		Args: args,
 		pos:  pos,
+		end:  end,
 	}
 }
 
@@ -31,6 +33,18 @@ func (c Call) String() string {
 	return fmt.Sprintf("%v(%s)", c.Fn, strings.Join(args, ", "))
 }
 
+func (c Call) Pos() int {
+	return c.Fn.Pos()
+}
+
+func (c Call) End() int {
+	return c.end
+}
+
+func (c Call) Children() []Node {
+	return append([]Node{c.Fn}, c.Args...)
+}
+
 func (c Call) Compile(comp *patukek_compiler.Compiler) (p int, err error) {
 	if p, err = c.Fn.Compile(comp); err != nil {
 		return
@@ -43,7 +57,7 @@ func (c Call) Compile(comp *patukek_compiler.Compiler) (p int, err error) {
 	}
 
 	p = comp.Emit(patukek_code.OpCall, len(c.Args))
-	comp.Bookmark(c.pos)
+	comp.Bookmark(c.Pos(), c.End())
 	return
 }
 
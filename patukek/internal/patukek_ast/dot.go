@@ -0,0 +1,60 @@
+package patukek_ast
+
+import (
+	"fmt"
+
+	"patukek/internal/patukek_code"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_obj"
+)
+
+type Dot struct {
+	l    Node
+	name string
+	end  int
+}
+
+func NewDot(l Node, name string, end int) Node {
+	return Dot{
+		l:    l,
+		name: name,
+		end:  end,
+	}
+}
+
+func (d Dot) String() string {
+	return fmt.Sprintf("%v.%s", d.l, d.name)
+}
+
+func (d Dot) Pos() int {
+	return d.l.Pos()
+}
+
+func (d Dot) End() int {
+	return d.end
+}
+
+func (d Dot) Children() []Node {
+	return []Node{d.l}
+}
+
+func (d Dot) Left() Node {
+	return d.l
+}
+
+func (d Dot) Name() string {
+	return d.name
+}
+
+func (d Dot) Compile(c *patukek_compiler.Compiler) (position int, err error) {
+	if position, err = d.l.Compile(c); err != nil {
+		return
+	}
+	position = c.Emit(patukek_code.OpGetAttr, c.AddConstant(patukek_obj.NewString(d.name)))
+	c.Bookmark(d.Pos(), d.End())
+	return
+}
+
+func (d Dot) IsConstExpression() bool {
+	return false
+}
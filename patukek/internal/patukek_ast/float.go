@@ -0,0 +1,49 @@
+package patukek_ast
+
+import (
+	"patukek/internal/patukek_code"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_obj"
+)
+
+type Float struct {
+	v   float64
+	pos int
+	end int
+}
+
+func NewFloat(f float64, pos, end int) Node {
+	return Float{
+		v:   f,
+		pos: pos,
+		end: end,
+	}
+}
+
+func (f Float) String() string {
+	return patukek_obj.Float(f.v).String()
+}
+
+func (f Float) Pos() int {
+	return f.pos
+}
+
+func (f Float) End() int {
+	return f.end
+}
+
+func (f Float) Children() []Node {
+	return nil
+}
+
+func (f Float) Value() float64 {
+	return f.v
+}
+
+func (f Float) Compile(c *patukek_compiler.Compiler) (position int, err error) {
+	return c.Emit(patukek_code.OpConstant, c.AddConstant(patukek_obj.Float(f.v))), nil
+}
+
+func (f Float) IsConstExpression() bool {
+	return true
+}
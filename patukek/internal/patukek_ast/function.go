@@ -10,10 +10,13 @@ import (
 )
 
 type Function struct {
-	body   Node
-	Name   string
-	pos    int
-	params []Identifier
+	body    Node
+	Name    string
+	NamePos int
+	Doc     string
+	Result  *TypeExpr
+	pos     int
+	params  []Identifier
 }
 
 func NewFunction(params []Identifier, body Node, pos int) Node {
@@ -33,15 +36,39 @@ func (f Function) String() string {
 	return fmt.Sprintf("fn(%s) { %v }", strings.Join(params, ", "), f.body)
 }
 
+func (f Function) Pos() int {
+	return f.pos
+}
+
+func (f Function) End() int {
+	return f.body.End()
+}
+
+func (f Function) Children() []Node {
+	var ret []Node
+	for _, p := range f.params {
+		ret = append(ret, p)
+	}
+	return append(ret, f.body)
+}
+
+func (f Function) Params() []Identifier {
+	return f.params
+}
+
+func (f Function) Body() Node {
+	return f.body
+}
+
 func (f Function) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	c.EnterScope()
 
 	if f.Name != "" {
-		c.DefineFunctionName(f.Name)
+		c.DefineFunctionName(f.Name, f.NamePos)
 	}
 
 	for _, p := range f.params {
-		c.Define(p.String())
+		c.AddReference(p.name, p.pos, c.DefineAt(p.name, p.pos))
 	}
 
 	if position, err = f.body.Compile(c); err != nil {
@@ -65,10 +92,10 @@ func (f Function) Compile(c *patukek_compiler.Compiler) (position int, err error
 
 	fn := patukek_obj.NewFunctionCompiled(ins, nLocals, len(f.params), bookmarks)
 	position = c.Emit(patukek_code.OpClosure, c.AddConstant(fn), len(freeSymbols))
-	c.Bookmark(f.pos)
+	c.Bookmark(f.Pos(), f.End())
 	return
 }
 
 func (f Function) IsConstExpression() bool {
 	return false
-}
\ No newline at end of file
+}
@@ -7,6 +7,8 @@ import (
 type Identifier struct {
 	name string
 	pos  int
+	// Type is the annotation of a parameter or an assigned variable.
+	Type *TypeExpr
 }
 
 func NewIdentifier(name string, pos int) Identifier {
@@ -20,8 +22,21 @@ func (i Identifier) String() string {
 	return i.name
 }
 
+func (i Identifier) Pos() int {
+	return i.pos
+}
+
+func (i Identifier) End() int {
+	return i.pos + len(i.name)
+}
+
+func (i Identifier) Children() []Node {
+	return nil
+}
+
 func (i Identifier) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if symbol, ok := c.Resolve(i.name); ok {
+		c.AddReference(i.name, i.pos, symbol)
 		return c.LoadSymbol(symbol), nil
 	}
 	return 0, c.UnresolvedError(i.name, i.pos)
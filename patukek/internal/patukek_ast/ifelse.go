@@ -30,6 +30,37 @@ func (i IfExpr) String() string {
 	return fmt.Sprintf("if %v { %v }", i.cond, i.body)
 }
 
+func (i IfExpr) Pos() int {
+	return i.pos
+}
+
+func (i IfExpr) End() int {
+	if i.altern != nil {
+		return i.altern.End()
+	}
+	return i.body.End()
+}
+
+func (i IfExpr) Children() []Node {
+	if i.altern != nil {
+		return []Node{i.cond, i.body, i.altern}
+	}
+	return []Node{i.cond, i.body}
+}
+
+func (i IfExpr) Cond() Node {
+	return i.cond
+}
+
+func (i IfExpr) Body() Node {
+	return i.body
+}
+
+// Else returns the else branch, a block or another IfExpr, or nil.
+func (i IfExpr) Else() Node {
+	return i.altern
+}
+
 func (i IfExpr) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = i.cond.Compile(c); err != nil {
 		return
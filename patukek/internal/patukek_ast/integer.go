@@ -8,18 +8,42 @@ import (
 	"patukek/internal/patukek_obj"
 )
 
-type Integer int64
+type Integer struct {
+	v   int64
+	pos int
+	end int
+}
 
-func NewInteger(i int64) Node {
-	return Integer(i)
+func NewInteger(i int64, pos, end int) Node {
+	return Integer{
+		v:   i,
+		pos: pos,
+		end: end,
+	}
 }
 
 func (i Integer) String() string {
-	return strconv.FormatInt(int64(i), 10)
+	return strconv.FormatInt(i.v, 10)
+}
+
+func (i Integer) Pos() int {
+	return i.pos
+}
+
+func (i Integer) End() int {
+	return i.end
+}
+
+func (i Integer) Children() []Node {
+	return nil
+}
+
+func (i Integer) Value() int64 {
+	return i.v
 }
 
 func (i Integer) Compile(c *patukek_compiler.Compiler) (position int, err error) {
-	return c.Emit(patukek_code.OpConstant, c.AddConstant(patukek_obj.Integer(i))), nil
+	return c.Emit(patukek_code.OpConstant, c.AddConstant(patukek_obj.Integer(i.v))), nil
 }
 
 func (i Integer) IsConstExpression() bool {
@@ -8,21 +8,24 @@ import (
 	"patukek/internal/patukek_compiler"
 )
 
-type List []Node
-
-func NewList(elements ...Node) Node {
-	var ret List
+type List struct {
+	elems []Node
+	pos   int
+	end   int
+}
 
-	for _, e := range elements {
-		ret = append(ret, e)
+func NewList(pos, end int, elements ...Node) Node {
+	return List{
+		elems: elements,
+		pos:   pos,
+		end:   end,
 	}
-	return ret
 }
 
 func (l List) String() string {
 	var elements []string
 
-	for _, e := range l {
+	for _, e := range l.elems {
 		if s, ok := e.(String); ok {
 			elements = append(elements, s.Quoted())
 		} else {
@@ -32,13 +35,29 @@ func (l List) String() string {
 	return fmt.Sprintf("[%s]", strings.Join(elements, ", "))
 }
 
+func (l List) Pos() int {
+	return l.pos
+}
+
+func (l List) End() int {
+	return l.end
+}
+
+func (l List) Children() []Node {
+	return l.elems
+}
+
+func (l List) Elems() []Node {
+	return l.elems
+}
+
 func (l List) Compile(c *patukek_compiler.Compiler) (position int, err error) {
-	for _, n := range l {
+	for _, n := range l.elems {
 		if position, err = n.Compile(c); err != nil {
 			return
 		}
 	}
-	position = c.Emit(patukek_code.OpList, len(l))
+	position = c.Emit(patukek_code.OpList, len(l.elems))
 	return
 }
 
@@ -26,6 +26,26 @@ func (a And) String() string {
 	return fmt.Sprintf("(%v && %v)", a.l, a.r)
 }
 
+func (a And) Pos() int {
+	return a.l.Pos()
+}
+
+func (a And) End() int {
+	return a.r.End()
+}
+
+func (a And) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{a.l, a.r}
+}
+
+func (a And) Op() string {
+	return "&&"
+}
+
+func (a And) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return a.l, a.r
+}
+
 func (a And) Compile(c *patukek_compiler.Compiler) (p int, err error) {
 	if p, err = a.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (a And) Compile(c *patukek_compiler.Compiler) (p int, err error) {
 		return
 	}
 	p = c.Emit(patukek_code.OpAnd)
-	c.Bookmark(a.pos)
+	c.Bookmark(a.Pos(), a.End())
 	return
 }
 
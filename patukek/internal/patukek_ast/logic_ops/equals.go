@@ -26,6 +26,26 @@ func (e Equals) String() string {
 	return fmt.Sprintf("(%v == %v)", e.l, e.r)
 }
 
+func (e Equals) Pos() int {
+	return e.l.Pos()
+}
+
+func (e Equals) End() int {
+	return e.r.End()
+}
+
+func (e Equals) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{e.l, e.r}
+}
+
+func (e Equals) Op() string {
+	return "=="
+}
+
+func (e Equals) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return e.l, e.r
+}
+
 func (e Equals) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = e.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (e Equals) Compile(c *patukek_compiler.Compiler) (position int, err error)
 		return
 	}
 	position = c.Emit(patukek_code.OpEqual)
-	c.Bookmark(e.pos)
+	c.Bookmark(e.Pos(), e.End())
 	return
 }
 
@@ -26,6 +26,26 @@ func (g Greater) String() string {
 	return fmt.Sprintf("(%v > %v)", g.l, g.r)
 }
 
+func (g Greater) Pos() int {
+	return g.l.Pos()
+}
+
+func (g Greater) End() int {
+	return g.r.End()
+}
+
+func (g Greater) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{g.l, g.r}
+}
+
+func (g Greater) Op() string {
+	return ">"
+}
+
+func (g Greater) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return g.l, g.r
+}
+
 func (g Greater) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = g.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (g Greater) Compile(c *patukek_compiler.Compiler) (position int, err error)
 		return
 	}
 	position = c.Emit(patukek_code.OpGreaterThan)
-	c.Bookmark(g.pos)
+	c.Bookmark(g.Pos(), g.End())
 	return
 }
 
@@ -26,6 +26,26 @@ func (g GreaterEq) String() string {
 	return fmt.Sprintf("(%v >= %v)", g.l, g.r)
 }
 
+func (g GreaterEq) Pos() int {
+	return g.l.Pos()
+}
+
+func (g GreaterEq) End() int {
+	return g.r.End()
+}
+
+func (g GreaterEq) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{g.l, g.r}
+}
+
+func (g GreaterEq) Op() string {
+	return ">="
+}
+
+func (g GreaterEq) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return g.l, g.r
+}
+
 func (g GreaterEq) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = g.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (g GreaterEq) Compile(c *patukek_compiler.Compiler) (position int, err erro
 		return
 	}
 	position = c.Emit(patukek_code.OpGreaterThanEqual)
-	c.Bookmark(g.pos)
+	c.Bookmark(g.Pos(), g.End())
 	return
 }
 
@@ -26,6 +26,26 @@ func (l Less) String() string {
 	return fmt.Sprintf("(%v < %v)", l.l, l.r)
 }
 
+func (l Less) Pos() int {
+	return l.l.Pos()
+}
+
+func (l Less) End() int {
+	return l.r.End()
+}
+
+func (l Less) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{l.l, l.r}
+}
+
+func (l Less) Op() string {
+	return "<"
+}
+
+func (l Less) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return l.l, l.r
+}
+
 func (l Less) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = l.r.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (l Less) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 		return
 	}
 	position = c.Emit(patukek_code.OpGreaterThan)
-	c.Bookmark(l.pos)
+	c.Bookmark(l.Pos(), l.End())
 	return
 }
 
@@ -26,6 +26,26 @@ func (l LessEq) String() string {
 	return fmt.Sprintf("(%v <= %v)", l.l, l.r)
 }
 
+func (l LessEq) Pos() int {
+	return l.l.Pos()
+}
+
+func (l LessEq) End() int {
+	return l.r.End()
+}
+
+func (l LessEq) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{l.l, l.r}
+}
+
+func (l LessEq) Op() string {
+	return "<="
+}
+
+func (l LessEq) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return l.l, l.r
+}
+
 func (l LessEq) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = l.r.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (l LessEq) Compile(c *patukek_compiler.Compiler) (position int, err error)
 		return
 	}
 	position = c.Emit(patukek_code.OpGreaterThanEqual)
-	c.Bookmark(l.pos)
+	c.Bookmark(l.Pos(), l.End())
 	return
 }
 
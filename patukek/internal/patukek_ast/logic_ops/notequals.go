@@ -26,6 +26,26 @@ func (n NotEquals) String() string {
 	return fmt.Sprintf("(%v != %v)", n.l, n.r)
 }
 
+func (n NotEquals) Pos() int {
+	return n.l.Pos()
+}
+
+func (n NotEquals) End() int {
+	return n.r.End()
+}
+
+func (n NotEquals) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{n.l, n.r}
+}
+
+func (n NotEquals) Op() string {
+	return "!="
+}
+
+func (n NotEquals) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return n.l, n.r
+}
+
 func (n NotEquals) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = n.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (n NotEquals) Compile(c *patukek_compiler.Compiler) (position int, err erro
 		return
 	}
 	position = c.Emit(patukek_code.OpNotEqual)
-	c.Bookmark(n.pos)
+	c.Bookmark(n.Pos(), n.End())
 	return
 }
 
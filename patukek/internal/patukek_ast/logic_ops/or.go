@@ -26,6 +26,26 @@ func (o Or) String() string {
 	return fmt.Sprintf("(%v || %v)", o.l, o.r)
 }
 
+func (o Or) Pos() int {
+	return o.l.Pos()
+}
+
+func (o Or) End() int {
+	return o.r.End()
+}
+
+func (o Or) Children() []patukek_ast.Node {
+	return []patukek_ast.Node{o.l, o.r}
+}
+
+func (o Or) Op() string {
+	return "||"
+}
+
+func (o Or) Operands() (patukek_ast.Node, patukek_ast.Node) {
+	return o.l, o.r
+}
+
 func (o Or) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if position, err = o.l.Compile(c); err != nil {
 		return
@@ -34,7 +54,7 @@ func (o Or) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 		return
 	}
 	position = c.Emit(patukek_code.OpOr)
-	c.Bookmark(o.pos)
+	c.Bookmark(o.Pos(), o.End())
 	return
 }
 
@@ -2,11 +2,31 @@ package patukek_ast
 
 import (
 	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_err"
 )
 
 type parseFn func(string, string) (Node, []error)
 
+// Node is a node of the syntax tree. Pos and End are the byte offsets of
+// its first character and of the character following it, and Children
+// returns the nodes directly below it in source order.
 type Node interface {
 	String() string
+	Pos() int
+	End() int
+	Children() []Node
 	patukek_compiler.Compilable
-}
\ No newline at end of file
+}
+
+// BinaryOp is implemented by the infix operator nodes.
+type BinaryOp interface {
+	Node
+	Op() string
+	Operands() (Node, Node)
+}
+
+// SpanOf returns the line and column span of n in the source indexed by
+// lines.
+func SpanOf(n Node, lines *patukek_err.Lines) patukek_err.Span {
+	return lines.Span(n.Pos(), n.End())
+}
@@ -20,15 +20,43 @@ func NewReturn(n Node, pos int) Node {
 }
 
 func (r Return) String() string {
+	if r.v == nil {
+		return "return"
+	}
 	return fmt.Sprintf("return %v", r.v)
 }
 
+func (r Return) Pos() int {
+	return r.pos
+}
+
+func (r Return) End() int {
+	if r.v == nil {
+		return r.pos + len("return")
+	}
+	return r.v.End()
+}
+
+func (r Return) Children() []Node {
+	if r.v == nil {
+		return nil
+	}
+	return []Node{r.v}
+}
+
+// Value returns the returned expression, or nil for a bare return.
+func (r Return) Value() Node {
+	return r.v
+}
+
 func (r Return) Compile(c *patukek_compiler.Compiler) (position int, err error) {
-	if position, err = r.v.Compile(c); err != nil {
+	if r.v == nil {
+		c.Emit(patukek_code.OpNull)
+	} else if position, err = r.v.Compile(c); err != nil {
 		return
 	}
 	position = c.Emit(patukek_code.OpReturnValue)
-	c.Bookmark(r.pos)
+	c.Bookmark(r.Pos(), r.End())
 	return
 }
 
@@ -12,6 +12,7 @@ import (
 )
 
 type String struct {
+	raw    string
 	s      string
 	parse  parseFn
 	substr []Node
@@ -24,15 +25,35 @@ func NewString(file, s string, parse parseFn, pos int) (Node, error) {
 		return nil, err
 	}
 
-	i := newInterpolator(file, str, parse)
+	i := newInterpolator(file, str, parse, pos+1)
 	nodes, str, err := i.nodes()
-	return String{s: str, parse: parse, substr: nodes, pos: pos}, err
+	if len(nodes) == 0 {
+		str = strings.ReplaceAll(str, "%%", "%")
+	}
+	return String{raw: s, s: str, parse: parse, substr: nodes, pos: pos}, err
 }
 
 func (s String) String() string {
 	return s.s
 }
 
+func (s String) Pos() int {
+	return s.pos
+}
+
+func (s String) End() int {
+	return s.pos + len(s.raw) + len(`""`)
+}
+
+func (s String) Children() []Node {
+	return s.substr
+}
+
+// Raw returns the literal as written between the quotes.
+func (s String) Raw() string {
+	return s.raw
+}
+
 func (s String) Quoted() string {
 	return strconv.Quote(s.s)
 }
@@ -40,7 +61,7 @@ func (s String) Quoted() string {
 func (s String) Compile(c *patukek_compiler.Compiler) (position int, err error) {
 	if len(s.substr) == 0 {
 		position = c.Emit(patukek_code.OpConstant, c.AddConstant(patukek_obj.NewString(s.s)))
-		c.Bookmark(s.pos)
+		c.Bookmark(s.Pos(), s.End())
 		return
 	}
 
@@ -52,7 +73,7 @@ func (s String) Compile(c *patukek_compiler.Compiler) (position int, err error)
 	}
 
 	position = c.Emit(patukek_code.OpInterpolate, c.AddConstant(patukek_obj.NewString(s.s)), len(s.substr))
-	c.Bookmark(s.pos)
+	c.Bookmark(s.Pos(), s.End())
 	return
 }
 
@@ -80,6 +101,7 @@ type interpolator struct {
 	s          string
 	file       string
 	parse      parseFn
+	offset     int
 	pos        int
 	width      int
 	nblocks    int
@@ -88,8 +110,10 @@ type interpolator struct {
 	strings.Builder
 }
 
-func newInterpolator(file, s string, parse parseFn) interpolator {
-	return interpolator{s: s, file: file, parse: parse}
+// newInterpolator returns an interpolator for s, which starts at offset
+// in the source file.
+func newInterpolator(file, s string, parse parseFn, offset int) interpolator {
+	return interpolator{s: s, file: file, parse: parse, offset: offset}
 }
 
 func (i *interpolator) next() (r rune) {
@@ -182,6 +206,7 @@ func (i *interpolator) nodes() ([]Node, string, error) {
 				goto tail
 			}
 
+			start := i.pos
 			s, err := i.acceptUntil('{', '}')
 			if err != nil {
 				return []Node{}, "", err
@@ -189,10 +214,15 @@ func (i *interpolator) nodes() ([]Node, string, error) {
 				continue
 			}
 
-			tree, errs := i.parse(i.file, s)
+			// Pad the expression so that its nodes have their offsets in
+			// the source file.
+			tree, errs := i.parse(i.file, strings.Repeat(" ", i.offset+start)+s)
 			if len(errs) > 0 {
 				return []Node{}, "", i.parserError(errs)
 			}
+			if b, ok := tree.(*Block); ok {
+				b.pos = i.offset + start
+			}
 
 			nodes = append(nodes, tree)
 			i.WriteString("%v")
@@ -205,6 +235,9 @@ func (i *interpolator) nodes() ([]Node, string, error) {
 		}
 
 	tail:
+		if r == '%' {
+			i.WriteByte('%')
+		}
 		i.WriteRune(r)
 	}
 
@@ -0,0 +1,72 @@
+package patukek_ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeExpr is a type annotation, such as "int", "list[string]" or
+// "patukek(int, int) -> int". Elem is the element type of a list, Params
+// and Result describe a function type; a nil Result means any result.
+// A parameter of a function type may be Optional, written "[int]", or
+// Variadic, written "int...".
+type TypeExpr struct {
+	Name     string
+	Elem     *TypeExpr
+	Params   []*TypeExpr
+	Result   *TypeExpr
+	Optional bool
+	Variadic bool
+	pos      int
+	end      int
+}
+
+func NewTypeExpr(name string, pos, end int) *TypeExpr {
+	return &TypeExpr{Name: name, pos: pos, end: end}
+}
+
+func (t *TypeExpr) String() string {
+	switch {
+	case t.Optional:
+		return "[" + t.plain() + "]"
+	case t.Variadic:
+		return t.plain() + "..."
+	default:
+		return t.plain()
+	}
+}
+
+// plain returns t without the optional and variadic marks.
+func (t *TypeExpr) plain() string {
+	switch {
+	case t.Elem != nil:
+		return fmt.Sprintf("%s[%v]", t.Name, t.Elem)
+
+	case t.Name == "patukek":
+		var params []string
+		for _, p := range t.Params {
+			params = append(params, p.String())
+		}
+
+		s := fmt.Sprintf("patukek(%s)", strings.Join(params, ", "))
+		if t.Result != nil {
+			s += " -> " + t.Result.String()
+		}
+		return s
+
+	default:
+		return t.Name
+	}
+}
+
+func (t *TypeExpr) Pos() int {
+	return t.pos
+}
+
+func (t *TypeExpr) End() int {
+	return t.end
+}
+
+func (t *TypeExpr) SetEnd(end int) {
+	t.end = end
+}
@@ -0,0 +1,39 @@
+package patukek_ast
+
+// A Visitor's Visit method is called for every node met by Walk. If the
+// returned visitor w is not nil, Walk visits the children of the node
+// with w, then calls w.Visit(nil).
+type Visitor interface {
+	Visit(n Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at n in depth-first order.
+func Walk(v Visitor, n Node) {
+	if n == nil {
+		return
+	}
+	if v = v.Visit(n); v == nil {
+		return
+	}
+
+	for _, c := range n.Children() {
+		Walk(v, c)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at n in depth-first order, calling
+// f for every node and then f(nil) after its children. The children of
+// a node are skipped if f returns false for it.
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
@@ -0,0 +1,123 @@
+package patukek_ast_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_astjson"
+	"patukek/internal/patukek_parser"
+)
+
+const walkSrc = "x = f(1, a + 2)\ng = patukek(n) { if n { -n } }"
+
+func parse(t *testing.T, src string) patukek_ast.Node {
+	t.Helper()
+
+	tree, errs := patukek_parser.Parse("test.ptk", src)
+	if len(errs) > 0 {
+		t.Fatalf("Parse(%q): %v", src, errs)
+	}
+	return tree
+}
+
+// label names n in the visit orders below: identifiers and literals by
+// their text, operators by their symbol, other nodes by their kind, and
+// the end of the children of a node with ".".
+func label(n patukek_ast.Node) string {
+	switch n := n.(type) {
+	case nil:
+		return "."
+	case patukek_ast.Identifier, patukek_ast.Integer:
+		return n.String()
+	case patukek_ast.BinaryOp:
+		return n.Op()
+	default:
+		return patukek_astjson.Kind(n)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	var got []string
+	patukek_ast.Inspect(parse(t, walkSrc), func(n patukek_ast.Node) bool {
+		got = append(got, label(n))
+		return true
+	})
+
+	want := strings.Fields(`Block
+		Assign x . Call f . 1 . + a . 2 . . . .
+		Assign g . Function n . Block IfExpr n . Block Negative n . . . . . . .
+	.`)
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+}
+
+func TestInspectSkip(t *testing.T) {
+	var got []string
+	patukek_ast.Inspect(parse(t, walkSrc), func(n patukek_ast.Node) bool {
+		got = append(got, label(n))
+		switch n.(type) {
+		case patukek_ast.Call, patukek_ast.Function:
+			return false
+		}
+		return true
+	})
+
+	// Skipped nodes are not followed by the nil of the end of their
+	// children.
+	want := strings.Fields("Block Assign x . Call . Assign g . Function . .")
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+}
+
+// depthVisitor records the nodes it visits with their depth.
+type depthVisitor struct {
+	depth int
+	out   *[]string
+	stop  func(patukek_ast.Node) bool
+}
+
+func (v depthVisitor) Visit(n patukek_ast.Node) patukek_ast.Visitor {
+	*v.out = append(*v.out, fmt.Sprintf("%d:%s", v.depth, label(n)))
+	if n == nil || v.stop(n) {
+		return nil
+	}
+	return depthVisitor{depth: v.depth + 1, out: v.out, stop: v.stop}
+}
+
+func TestWalk(t *testing.T) {
+	tree := parse(t, "x = f(1)\ny = 2")
+
+	var got []string
+	patukek_ast.Walk(depthVisitor{out: &got, stop: func(patukek_ast.Node) bool { return false }}, tree)
+
+	// The children of a node and the nil after them are visited with the
+	// visitor returned for the node.
+	want := strings.Fields(`0:Block
+		1:Assign 2:x 3:. 2:Call 3:f 4:. 3:1 4:. 3:. 2:.
+		1:Assign 2:y 3:. 2:2 3:. 2:.
+	1:.`)
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+
+	got = nil
+	patukek_ast.Walk(depthVisitor{out: &got, stop: func(n patukek_ast.Node) bool {
+		_, ok := n.(patukek_ast.Assign)
+		return ok
+	}}, tree)
+
+	if want := strings.Fields("0:Block 1:Assign 1:Assign 1:."); !slices.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+
+	got = nil
+	patukek_ast.Walk(depthVisitor{out: &got}, nil)
+	if len(got) != 0 {
+		t.Errorf("Walk of a nil tree visited %v", got)
+	}
+}
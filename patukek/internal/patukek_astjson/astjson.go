@@ -0,0 +1,133 @@
+package patukek_astjson
+
+import (
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_ast/calc_ops"
+	"patukek/internal/patukek_ast/logic_ops"
+	"patukek/internal/patukek_err"
+)
+
+// Version is the version of the schema. It changes whenever a kind or
+// a field is renamed or removed.
+const Version = 1
+
+// File is the JSON form of a parsed source file.
+type File struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Root    *Node  `json:"root"`
+}
+
+// Node is the JSON form of an AST node. Children are listed in source
+// order:
+//
+//	Block       statements
+//	Assign      target, value
+//	Call        callee, arguments
+//	Dot         object (the member is in name)
+//	Function    parameters, body
+//	IfExpr      condition, then block, else block or IfExpr if any
+//	List        elements
+//	Return      value if any
+//	String      interpolated expressions
+//	BinaryOp    left, right (the operator is in op)
+//	Negative    operand
+//
+// Type holds the annotation of an Identifier, or the annotated result of
+// a Function.
+type Node struct {
+	Kind     string           `json:"kind"`
+	Span     patukek_err.Span `json:"span"`
+	Name     string           `json:"name,omitempty"`
+	Op       string           `json:"op,omitempty"`
+	Value    any              `json:"value,omitempty"`
+	Doc      string           `json:"doc,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Children []*Node          `json:"children,omitempty"`
+}
+
+// New converts the tree parsed from src.
+func New(name, src string, tree patukek_ast.Node) *File {
+	return &File{
+		Version: Version,
+		Name:    name,
+		Root:    convert(tree, patukek_err.NewLines(src)),
+	}
+}
+
+func convert(n patukek_ast.Node, lines *patukek_err.Lines) *Node {
+	ret := &Node{
+		Kind: Kind(n),
+		Span: patukek_ast.SpanOf(n, lines),
+	}
+
+	switch n := n.(type) {
+	case patukek_ast.Identifier:
+		ret.Name = n.String()
+		if n.Type != nil {
+			ret.Type = n.Type.String()
+		}
+	case patukek_ast.Integer:
+		ret.Value = n.Value()
+	case patukek_ast.Float:
+		ret.Value = n.Value()
+	case patukek_ast.String:
+		ret.Value = n.Raw()
+	case patukek_ast.Dot:
+		ret.Name = n.Name()
+	case patukek_ast.Assign:
+		ret.Doc = n.Doc
+	case patukek_ast.Function:
+		ret.Name, ret.Doc = n.Name, n.Doc
+		if n.Result != nil {
+			ret.Type = n.Result.String()
+		}
+	case patukek_ast.BinaryOp:
+		ret.Op = n.Op()
+	case calc_ops.Negative:
+		ret.Op = "-"
+	}
+
+	for _, c := range n.Children() {
+		ret.Children = append(ret.Children, convert(c, lines))
+	}
+	return ret
+}
+
+// Kind returns the kind of n in the schema.
+func Kind(n patukek_ast.Node) string {
+	switch n.(type) {
+	case *patukek_ast.Block:
+		return "Block"
+	case patukek_ast.Assign:
+		return "Assign"
+	case patukek_ast.Call:
+		return "Call"
+	case patukek_ast.Dot:
+		return "Dot"
+	case patukek_ast.Float:
+		return "Float"
+	case patukek_ast.Function:
+		return "Function"
+	case patukek_ast.Identifier:
+		return "Identifier"
+	case patukek_ast.IfExpr:
+		return "IfExpr"
+	case patukek_ast.Integer:
+		return "Integer"
+	case patukek_ast.List:
+		return "List"
+	case patukek_ast.Return:
+		return "Return"
+	case patukek_ast.String:
+		return "String"
+	case calc_ops.Negative:
+		return "Negative"
+	case calc_ops.Plus, calc_ops.Minus, calc_ops.Times, calc_ops.Divide, calc_ops.Mod,
+		logic_ops.Equals, logic_ops.NotEquals, logic_ops.Less, logic_ops.LessEq,
+		logic_ops.Greater, logic_ops.GreaterEq, logic_ops.And, logic_ops.Or:
+		return "BinaryOp"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,40 @@
+// Package patukek_build turns patukek source into bytecode. Every command
+// that runs programs goes through it, so they all accept the same ones.
+package patukek_build
+
+import (
+	"errors"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_parser"
+	"patukek/internal/patukek_types"
+)
+
+// Check parses src and checks its types, recording them in info unless it
+// is nil. The returned tree is nil when src does not parse.
+func Check(file, src string, info *patukek_types.Info) (patukek_ast.Node, []error) {
+	tree, errs := patukek_parser.Parse(file, src)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return tree, patukek_types.CheckInfo(file, src, tree, info)
+}
+
+// Compile compiles tree, parsed from src, with c.
+func Compile(c *patukek_compiler.Compiler, file, src string, tree patukek_ast.Node) (*patukek_compiler.Bytecode, error) {
+	c.SetFileInfo(file, src)
+	if err := c.Compile(tree); err != nil {
+		return nil, err
+	}
+	return c.Bytecode(), nil
+}
+
+// Build checks src and compiles it with a new compiler.
+func Build(file, src string) (*patukek_compiler.Bytecode, error) {
+	tree, errs := Check(file, src, nil)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return Compile(patukek_compiler.New(), file, src, tree)
+}
@@ -48,6 +48,8 @@ const (
 	OpGetBuiltin
 	OpGetFree
 	OpInterpolate
+	OpGetAttr
+	OpSetAttr
 	OpPop
 )
 
@@ -82,6 +84,9 @@ var definitions = map[Opcode]*Definition{
 	OpSetLocal:         {"OpSetLocal", []int{1}},
 	OpGetBuiltin:       {"OpGetBuiltin", []int{1}},
 	OpGetFree:          {"OpGetFree", []int{1}},
+	OpInterpolate:      {"OpInterpolate", []int{2, 1}},
+	OpGetAttr:          {"OpGetAttr", []int{2}},
+	OpSetAttr:          {"OpSetAttr", []int{2}},
 	OpPop:              {"OpPop", []int{}},
 }
 
@@ -92,9 +97,15 @@ func (ins Instructions) String() string {
 		def, err := Lookup(ins[i])
 		if err != nil {
 			_, _ = fmt.Fprintf(&out, "Error: %s\n", err)
+			i++
 			continue
 		}
 
+		if i+1+operandsLen(def) > len(ins) {
+			_, _ = fmt.Fprintf(&out, "Error: truncated operands for %s\n", def.Name)
+			break
+		}
+
 		operands, read := ReadOperands(def, ins[i+1:])
 		_, _ = fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
 		i += read + 1
@@ -169,6 +180,14 @@ func Make(op Opcode, operands ...int) []byte {
 	return instructions
 }
 
+func operandsLen(def *Definition) int {
+	var n int
+	for _, w := range def.OperandWidths {
+		n += w
+	}
+	return n
+}
+
 func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
 	operands := make([]int, len(def.OperandWidths))
 	offset := 0
@@ -0,0 +1,17 @@
+package patukek_code
+
+import (
+	"strings"
+	"testing"
+)
+
+// Regression: String used to loop forever on an undefined opcode and
+// read past the end of truncated operands.
+func TestInstructionsStringInvalid(t *testing.T) {
+	ins := Instructions{255, byte(OpConstant), 0}
+
+	s := ins.String()
+	if !strings.Contains(s, "opcode 255 undefined") || !strings.Contains(s, "truncated operands") {
+		t.Errorf("unexpected disassembly:\n%s", s)
+	}
+}
@@ -1,11 +1,13 @@
 package patukek_compiler
 
 import (
+	"errors"
 	"fmt"
 
 	"patukek/internal/patukek_code"
 	"patukek/internal/patukek_err"
 	"patukek/internal/patukek_obj"
+	"patukek/internal/patukek_suggest"
 )
 
 type Compilable interface {
@@ -25,12 +27,21 @@ type CompilationScope struct {
 	bookmarks    []patukek_err.Bookmark
 }
 
+// Reference is an identifier in the source and the symbol it resolved to.
+type Reference struct {
+	Name   string
+	Pos    int
+	Symbol Symbol
+}
+
 type Compiler struct {
 	constants   *[]patukek_obj.Object
 	scopes      []CompilationScope
 	scopeIndex  int
 	fileName    string
 	fileContent string
+	trackRefs   bool
+	References  []Reference
 	*SymbolTable
 }
 
@@ -54,6 +65,14 @@ func New() *Compiler {
 	}
 }
 
+func NewWithState(st *SymbolTable, constants *[]patukek_obj.Object) *Compiler {
+	return &Compiler{
+		SymbolTable: st,
+		scopes:      []CompilationScope{{}},
+		constants:   constants,
+	}
+}
+
 func (c *Compiler) AddConstant(o patukek_obj.Object) int {
 	*c.constants = append(*c.constants, o)
 	return len(*c.constants) - 1
@@ -138,21 +157,81 @@ func (c *Compiler) Pos() int {
 	return len(c.scopes[c.scopeIndex].instructions)
 }
 
-func (c *Compiler) Bookmark(pos int) {
+// Bookmark maps the instructions emitted so far to the source span from
+// start to end, for runtime errors.
+func (c *Compiler) Bookmark(start, end int) {
 	if c.fileContent == "" {
 		return
 	}
 
-	b := patukek_err.NewBookmark(c.fileContent, pos, c.Pos())
+	b := patukek_err.NewBookmark(c.fileContent, start, end, c.Pos())
 	c.scopes[c.scopeIndex].bookmarks = append(c.scopes[c.scopeIndex].bookmarks, b)
 }
 
+// UnresolvedError reports an undefined name, suggesting the closest
+// visible one and pointing at where it is declared.
 func (c *Compiler) UnresolvedError(name string, pos int) error {
+	msg := "undefined variable " + name
+
+	sym, ok := c.suggest(name)
+	if ok {
+		msg += fmt.Sprintf(", did you mean %s?", sym.Name)
+	}
+
 	if c.fileName == "" || c.fileContent == "" {
-		return fmt.Errorf("undefined variable %s", name)
+		return errors.New(msg)
 	}
 
-	return patukek_err.New(c.fileName, c.fileContent, pos, "undefined variable %s", name)
+	err := patukek_err.NewSpan(c.fileName, c.fileContent, pos, pos+len(name), "%s", msg)
+	if ok && c.declared(sym) {
+		err.(*patukek_err.Error).Note(c.fileContent, sym.Pos, sym.Pos+len(sym.Name), "%s is declared", sym.Name)
+	}
+	return err
+}
+
+// suggest returns the visible symbol whose name is closest to name.
+func (c *Compiler) suggest(name string) (Symbol, bool) {
+	var (
+		syms  = c.SymbolTable.Visible()
+		names = make([]string, len(syms))
+	)
+
+	for i, s := range syms {
+		names[i] = s.Name
+	}
+
+	n, ok := patukek_suggest.Closest(name, names)
+	if !ok {
+		return Symbol{}, false
+	}
+	for _, s := range syms {
+		if s.Name == n {
+			return s, true
+		}
+	}
+	return Symbol{}, false
+}
+
+// declared reports whether the declaration of s is in the file being
+// compiled. Builtins have none, and symbols kept from an earlier input
+// point into another source.
+func (c *Compiler) declared(s Symbol) bool {
+	if s.Scope == BuiltinScope || s.Pos < 0 || s.Pos+len(s.Name) > len(c.fileContent) {
+		return false
+	}
+	return c.fileContent[s.Pos:s.Pos+len(s.Name)] == s.Name
+}
+
+// TrackReferences makes the compiler record every resolved identifier in
+// References, for tools that map names to their definitions.
+func (c *Compiler) TrackReferences() {
+	c.trackRefs = true
+}
+
+func (c *Compiler) AddReference(name string, pos int, s Symbol) {
+	if c.trackRefs {
+		c.References = append(c.References, Reference{Name: name, Pos: pos, Symbol: s})
+	}
 }
 
 func (c *Compiler) Compile(node Compilable) error {
@@ -0,0 +1,27 @@
+package patukek_compiler_test
+
+import (
+	"testing"
+
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_parser"
+	"patukek/internal/patukek_seed"
+)
+
+func FuzzCompile(f *testing.F) {
+	patukek_seed.AddExamples(f)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		tree, errs := patukek_parser.Parse("fuzz.ptk", in)
+		if len(errs) > 0 {
+			return
+		}
+
+		c := patukek_compiler.New()
+		c.SetFileInfo("fuzz.ptk", in)
+		if err := c.Compile(tree); err != nil {
+			return
+		}
+		_ = c.Bytecode().Instructions.String()
+	})
+}
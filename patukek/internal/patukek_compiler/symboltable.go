@@ -1,5 +1,7 @@
 package patukek_compiler
 
+import "sort"
+
 type SymbolScope int
 
 const (
@@ -14,6 +16,7 @@ type Symbol struct {
 	Name  string
 	Scope SymbolScope
 	Index int
+	Pos   int
 }
 
 type SymbolTable struct {
@@ -35,7 +38,13 @@ func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
 }
 
 func (s *SymbolTable) Define(name string) Symbol {
-	if symbol, ok := s.Store[name]; ok {
+	return s.DefineAt(name, 0)
+}
+
+// DefineAt defines name like Define, recording pos as the source offset
+// of its first definition.
+func (s *SymbolTable) DefineAt(name string, pos int) Symbol {
+	if symbol, ok := s.Store[name]; ok && symbol.Scope != BuiltinScope {
 		return symbol
 	}
 
@@ -43,6 +52,7 @@ func (s *SymbolTable) Define(name string) Symbol {
 		Name:  name,
 		Index: s.NumDefs,
 		Scope: GlobalScope,
+		Pos:   pos,
 	}
 
 	if s.outer != nil {
@@ -85,13 +95,40 @@ func (s *SymbolTable) DefineFree(original Symbol) Symbol {
 		Name:  original.Name,
 		Index: len(s.FreeSymbols) - 1,
 		Scope: FreeScope,
+		Pos:   original.Pos,
 	}
 	s.Store[original.Name] = symbol
 	return symbol
 }
 
-func (s *SymbolTable) DefineFunctionName(n string) Symbol {
-	symbol := Symbol{Name: n, Index: 0, Scope: FunctionScope}
+func (s *SymbolTable) DefineFunctionName(n string, pos int) Symbol {
+	symbol := Symbol{Name: n, Index: 0, Scope: FunctionScope, Pos: pos}
 	s.Store[n] = symbol
 	return symbol
-}
\ No newline at end of file
+}
+
+// Visible returns the symbols that can be resolved from s, innermost
+// scope first and by name within a scope. Unlike Resolve it does not
+// define free symbols.
+func (s *SymbolTable) Visible() []Symbol {
+	var (
+		ret  []Symbol
+		seen = make(map[string]bool)
+	)
+
+	for t := s; t != nil; t = t.outer {
+		names := make([]string, 0, len(t.Store))
+		for n := range t.Store {
+			if !seen[n] {
+				names = append(names, n)
+				seen[n] = true
+			}
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			ret = append(ret, t.Store[n])
+		}
+	}
+	return ret
+}
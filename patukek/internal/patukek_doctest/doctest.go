@@ -0,0 +1,66 @@
+package patukek_doctest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"patukek/internal/patukek_build"
+	"patukek/internal/patukek_fmt"
+	"patukek/internal/patukek_vm"
+)
+
+// Example is a program found in a document together with the output
+// the document says it prints.
+type Example struct {
+	Name    string
+	Line    int
+	Program string
+	Want    string
+}
+
+// Run compiles and runs a program and returns what it printed, followed
+// by the error that stopped it, as "patukek run" would show them.
+func Run(name, src string) string {
+	var out bytes.Buffer
+
+	bc, err := patukek_build.Build(name, src)
+	if err != nil {
+		fmt.Fprintln(&out, err)
+		return out.String()
+	}
+
+	vm := patukek_vm.NewWithState(name, bc, patukek_vm.NewState(), patukek_vm.Config{
+		Stdin:  strings.NewReader(""),
+		Stdout: &out,
+		Stderr: &out,
+	})
+	if err := vm.Run(context.Background()); err != nil {
+		fmt.Fprintln(&out, err)
+	}
+	return out.String()
+}
+
+// Check runs the example and returns a diff from the documented output
+// to the actual one, or nil if they match. Trailing blank lines are not
+// significant.
+func (e Example) Check() []byte {
+	var (
+		want = normalize(e.Want)
+		got  = normalize(Run(e.Name, e.Program))
+	)
+
+	if want == got {
+		return nil
+	}
+	return patukek_fmt.Diff(e.Name, []byte(want), []byte(got))
+}
+
+func normalize(s string) string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return s
+	}
+	return s + "\n"
+}
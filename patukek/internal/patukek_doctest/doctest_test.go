@@ -0,0 +1,92 @@
+package patukek_doctest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const doc = `# Examples
+
+~~~patukek
+println(1 + 2)
+~~~
+
+~~~output
+3
+~~~
+
+## From the shell
+
+~~~
+$ patukek run examples/hello.ptk
+~~~
+
+Программа:
+
+~~~
+println("привет")
+~~~
+
+Результат:
+
+~~~
+привет
+~~~
+
+## Without output
+
+~~~patukek
+println(4)
+~~~
+`
+
+func TestExtract(t *testing.T) {
+	want := []Example{
+		{Name: "doc.md:3", Line: 3, Program: "println(1 + 2)", Want: "3"},
+		{Name: "examples/hello.ptk", Line: 19, Program: `println("привет")`, Want: "привет"},
+	}
+	if got := Extract("doc.md", doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract = %+v, want %+v", got, want)
+	}
+
+	backticks := "Program:\n\n```\nprintln(5)\n```\n\nOutput:\n\n```\n5\n```\n"
+	if got := Extract("doc.md", backticks); len(got) != 1 || got[0].Program != "println(5)" || got[0].Want != "5" {
+		t.Errorf("Extract of backtick fences = %+v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`println("a")`, "a\n"},
+		{"println(1)\nprintln(len(1, 2))", "len takes 1 argument, got 2"},
+		{"println(1)\nprintln(x)", "undefined variable x"},
+		{"println(", "expected next patukek_item"},
+	}
+
+	for _, tt := range tests {
+		if got := Run("test.ptk", tt.src); !strings.Contains(got, tt.want) {
+			t.Errorf("Run(%q) = %q, want %q in it", tt.src, got, tt.want)
+		}
+	}
+
+	if got := Run("test.ptk", "println(1)\nassert(1 == 2)"); !strings.HasPrefix(got, "1\n") || !strings.Contains(got, "assert") {
+		t.Errorf("Run of a failing program = %q, want its output and then its error", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	e := Example{Name: "test.ptk", Program: "println(1)\nprintln(2)", Want: "1\n2\n\n"}
+	if d := e.Check(); d != nil {
+		t.Errorf("Check of a matching example = %s", d)
+	}
+
+	e.Want = "1\n3"
+	d := e.Check()
+	if !strings.Contains(string(d), "-3") || !strings.Contains(string(d), "+2") {
+		t.Errorf("Check of a differing example = %q, want a diff from 3 to 2", d)
+	}
+}
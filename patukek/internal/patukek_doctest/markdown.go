@@ -0,0 +1,91 @@
+package patukek_doctest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Extract returns the examples of a Markdown document. A program is a
+// fenced block tagged "patukek" or introduced by a "Program:" line, and
+// its expected output is the next fenced block, tagged "output" or
+// introduced by an "Output:" line. The Russian labels of the project
+// README are accepted too.
+//
+// Programs are named after the last .ptk file mentioned in a shell
+// block of the same section, so that errors print the same file name
+// as the documented command, or after their position otherwise.
+func Extract(file, doc string) []Example {
+	var (
+		ret     []Example
+		lines   = strings.Split(doc, "\n")
+		label   string
+		name    string
+		program *Example
+	)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			name, label, program = "", "", nil
+			continue
+
+		case isFence(line):
+			var (
+				fence = line[:3]
+				info  = strings.TrimSpace(strings.TrimLeft(line, fence[:1]))
+				start = i + 1
+				body  []string
+			)
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				body = append(body, lines[i])
+			}
+			text := strings.Join(body, "\n")
+
+			switch {
+			case info == "patukek" || info == "ptk" || isLabel(label, programLabels):
+				n := name
+				if n == "" {
+					n = fmt.Sprintf("%s:%d", file, start)
+				}
+				program = &Example{Name: n, Line: start, Program: text}
+
+			case program != nil && (info == "output" || isLabel(label, outputLabels)):
+				program.Want = text
+				ret = append(ret, *program)
+				program = nil
+
+			case strings.HasPrefix(strings.TrimSpace(text), "$"):
+				for _, f := range strings.Fields(text) {
+					if strings.HasSuffix(f, ".ptk") {
+						name = f
+					}
+				}
+			}
+			label = ""
+
+		case line != "":
+			label = line
+		}
+	}
+	return ret
+}
+
+var (
+	programLabels = []string{"Program:", "Программа:"}
+	outputLabels  = []string{"Output:", "Результат:"}
+)
+
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+func isLabel(line string, labels []string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(line, l) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,19 +1,29 @@
 package patukek_err
 
+// Bookmark maps the instructions ending at Offset to the span of source
+// code, from Pos to End, they were compiled from.
 type Bookmark struct {
 	Offset int
+	Pos    int
+	End    int
 	LineNo int
-	pos    int
+	Column int
 	Line   string
+	col    int
+	width  int
 }
 
-func NewBookmark(fileCnt string, filePos, offset int) Bookmark {
-	bline, blineNo, relative := line(fileCnt, filePos)
+func NewBookmark(fileCnt string, start, end, offset int) Bookmark {
+	l := line(fileCnt, start)
 
 	return Bookmark{
 		Offset: offset,
-		Line:   bline,
-		LineNo: blineNo,
-		pos:    relative,
+		Pos:    start,
+		End:    max(start, end),
+		Line:   l.text,
+		LineNo: l.no,
+		Column: l.column,
+		col:    l.col,
+		width:  l.width(end),
 	}
-}
\ No newline at end of file
+}
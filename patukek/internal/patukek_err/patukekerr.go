@@ -5,20 +5,44 @@ import (
 	"strings"
 )
 
+// Error is an error located in a source file. Pos and End delimit the
+// offending span as byte offsets; Line and Column locate Pos.
+type Error struct {
+	File   string
+	Pos    int
+	End    int
+	Line   int
+	Column int
+	Msg    string
+	text   string
+}
+
+func (e *Error) Error() string {
+	return e.text
+}
+
 func New(file, input string, pos int, s string, a ...any) error {
+	return NewSpan(file, input, pos, pos+1, s, a...)
+}
+
+// NewSpan returns an error whose banner underlines the source from
+// start to end, or up to the end of the line if the span is longer.
+func NewSpan(file, input string, start, end int, s string, a ...any) error {
 	if file == "" {
 		file = "<stdin>"
 	}
 
-	nline, lineno, rel := line(input, pos)
-	return fmt.Errorf(
-		"patukek! error in file %s at line %d:\n    %s\n    %s\n%s",
-		file,
-		lineno,
-		nline,
-		arrow(rel),
-		fmt.Sprintf(s, a...),
-	)
+	l := line(input, start)
+	msg := fmt.Sprintf(s, a...)
+	return &Error{
+		File:   file,
+		Pos:    start,
+		End:    max(start, end),
+		Line:   l.no,
+		Column: l.column,
+		Msg:    msg,
+		text:   banner(file, l.no, l.text, l.col, l.width(end), msg),
+	}
 }
 
 func NewFromBookmark(file string, b Bookmark, s string, a ...any) error {
@@ -26,21 +50,113 @@ func NewFromBookmark(file string, b Bookmark, s string, a ...any) error {
 		return fmt.Errorf(s, a...)
 	}
 
-	return fmt.Errorf(
+	msg := fmt.Sprintf(s, a...)
+	return &Error{
+		File:   file,
+		Pos:    b.Pos,
+		End:    b.End,
+		Line:   b.LineNo,
+		Column: b.Column,
+		Msg:    msg,
+		text:   banner(file, b.LineNo, b.Line, b.col, b.width, msg),
+	}
+}
+
+func banner(file string, lineno int, line string, col, width int, msg string) string {
+	return fmt.Sprintf(
 		"patukek! error in file %s at line %d:\n    %s\n    %s\n%s",
 		file,
-		b.LineNo,
-		b.Line,
-		arrow(b.pos),
+		lineno,
+		expandTabs(line),
+		underline(line, col, width),
+		msg,
+	)
+}
+
+// Note adds to the banner of e a remark about another span of the same
+// file, such as the declaration of a name the error refers to.
+func (e *Error) Note(input string, start, end int, s string, a ...any) {
+	l := line(input, start)
+	e.text += fmt.Sprintf(
+		"\nnote: %s at line %d:\n    %s\n    %s",
 		fmt.Sprintf(s, a...),
+		l.no,
+		expandTabs(l.text),
+		underline(l.text, l.col, l.width(end)),
 	)
 }
 
-func line(input string, pos int) (line string, lineno, relative int) {
+// maxTrace is the number of lines of a call trace shown in an error.
+const maxTrace = 20
+
+// NewWithTrace is NewFromBookmark followed by the lines of the calls in
+// trace, innermost first. Consecutive calls from the same line are shown
+// once, as recursion otherwise fills the trace up to the call depth limit.
+func NewWithTrace(file string, b Bookmark, trace []Bookmark, s string, a ...any) error {
+	err := NewFromBookmark(file, b, s, a...)
+
+	e, ok := err.(*Error)
+	if !ok || len(trace) == 0 {
+		return err
+	}
+
+	var buf strings.Builder
+	buf.WriteString(e.text)
+	buf.WriteString("\ncalled from:")
+	for i, n := 0, 0; i < len(trace); i++ {
+		if n == maxTrace {
+			fmt.Fprintf(&buf, "\n    ... %d more", len(trace)-i)
+			break
+		}
+
+		t := trace[i]
+		fmt.Fprintf(&buf, "\n    %s:%d: %s", file, t.LineNo, t.Line)
+		n++
+
+		// Recursive calls from the same line are shown once.
+		j := i + 1
+		for j < len(trace) && trace[j].LineNo == t.LineNo {
+			j++
+		}
+		if j-i > 1 {
+			fmt.Fprintf(&buf, "\n    ... %d more", j-i-1)
+			i = j - 1
+		}
+	}
+	e.text = buf.String()
+	return e
+}
+
+// srcLine is the line of a source file containing an offset.
+type srcLine struct {
+	text   string // the line without its indentation
+	no     int
+	column int // of the offset in the whole line
+	col    int // of the offset in text
+	end    int // offset of the end of the line in the file
+	pos    int
+}
+
+func line(input string, pos int) srcLine {
+	pos = min(max(pos, 0), len(input))
 	s, e := start(input, pos), end(input, pos)
-	l := input[s:e]
-	line = strings.TrimLeft(l, " \t")
-	return line, lineNo(input, pos), len(line) - (e - pos)
+	text := strings.TrimLeft(input[s:e], " \t")
+
+	return srcLine{
+		text:   text,
+		no:     lineNo(input, pos),
+		column: pos - s + 1,
+		// A position in the indentation points at the first character.
+		col: max(len(text)-(e-pos), 0),
+		end: e,
+		pos: max(pos, e-len(text)),
+	}
+}
+
+// width returns how many bytes of the line a span ending at end covers,
+// at least one.
+func (l srcLine) width(end int) int {
+	return max(min(end, l.end)-l.pos, 1)
 }
 
 func start(s string, pos int) int {
@@ -73,15 +189,38 @@ func lineNo(s string, pos int) int {
 	return cnt
 }
 
-func arrow(pos int) string {
-	var s = make([]byte, pos+1)
+// tab is how tabs are shown in error banners, so that the underline
+// lines up whatever the terminal's tab width.
+const tab = "    "
+
+// underline returns a line of carets under width bytes of line starting
+// at col, for the line as shown by expandTabs.
+func underline(line string, col, width int) string {
+	var buf strings.Builder
 
-	for i := range s {
-		if i == pos {
-			s[i] = '^'
+	col = min(col, len(line))
+	end := min(col+width, len(line))
+	for _, r := range line[:col] {
+		if r == '\t' {
+			buf.WriteString(tab)
 		} else {
-			s[i] = ' '
+			buf.WriteByte(' ')
 		}
 	}
-	return string(s)
-}
\ No newline at end of file
+
+	if col == end {
+		buf.WriteByte('^')
+	}
+	for _, r := range line[col:end] {
+		if r == '\t' {
+			buf.WriteString(strings.Repeat("^", len(tab)))
+		} else {
+			buf.WriteByte('^')
+		}
+	}
+	return buf.String()
+}
+
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", tab)
+}
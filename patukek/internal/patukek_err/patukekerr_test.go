@@ -0,0 +1,157 @@
+package patukek_err
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewSpan(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		start, end int
+		want       string // the source line and its underline
+	}{
+		{"word", "x = foo + 1", 4, 7, "    x = foo + 1\n        ^^^"},
+		{"empty span", "x = foo + 1", 4, 4, "    x = foo + 1\n        ^"},
+		{"reversed span", "x = foo + 1", 4, 2, "    x = foo + 1\n        ^"},
+		{"whole line", "x = foo + 1", 0, 11, "    x = foo + 1\n    ^^^^^^^^^^^"},
+		{"indentation", "f(\n\t\tx + y)", 5, 6, "    x + y)\n    ^"},
+		{"in the indentation", "f(\n\t\tx + y)", 3, 6, "    x + y)\n    ^"},
+		{"tab before", "x =\tfoo\t+ 1", 4, 7, "    x =    foo    + 1\n           ^^^"},
+		{"tab inside", "x =\tfoo\t+ 1", 3, 8, "    x =    foo    + 1\n       ^^^^^^^^^^^"},
+		{"unicode", `s = "мир" + x`, 15, 16, "    s = \"мир\" + x\n                ^"},
+		{"unicode span", `s = "мир" + x`, 4, 12, "    s = \"мир\" + x\n        ^^^^^"},
+		{"multi-line span", "f(1,\n  2)\nx", 0, 9, "    f(1,\n    ^^^^"},
+		{"second line", "a\nbb = 1", 2, 4, "    bb = 1\n    ^^"},
+		{"eof", "x = (", 5, 6, "    x = (\n         ^"},
+		{"eof after newline", "x\n", 2, 3, "    \n    ^"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSpan("test.ptk", tt.src, tt.start, tt.end, "bad %s", "thing")
+
+			l := NewLines(tt.src).Position(tt.start)
+			want := fmt.Sprintf("patukek! error in file test.ptk at line %d:\n%s\nbad thing", l.Line, tt.want)
+			if got := err.Error(); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("%T is not an *Error", err)
+			}
+			if e.File != "test.ptk" || e.Line != l.Line || e.Column != l.Column || e.Msg != "bad thing" {
+				t.Errorf("got %+v, want line %d, column %d", e, l.Line, l.Column)
+			}
+			if e.Pos != l.Offset || e.End != max(l.Offset, tt.end) {
+				t.Errorf("got the span [%d, %d), want [%d, %d)", e.Pos, e.End, l.Offset, max(l.Offset, tt.end))
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	err := New("", "x = y", 4, "undefined variable %s", "y")
+	want := "patukek! error in file <stdin> at line 1:\n    x = y\n        ^\nundefined variable y"
+	if err.Error() != want {
+		t.Errorf("got\n%s\nwant\n%s", err, want)
+	}
+}
+
+func TestNote(t *testing.T) {
+	const src = "x: int = 1\n\tx = \"a\""
+
+	err := NewSpan("test.ptk", src, 16, 19, "cannot use string as int")
+	err.(*Error).Note(src, 0, 6, "x is declared")
+
+	want := "patukek! error in file test.ptk at line 2:\n" +
+		"    x = \"a\"\n" +
+		"        ^^^\n" +
+		"cannot use string as int\n" +
+		"note: x is declared at line 1:\n" +
+		"    x: int = 1\n" +
+		"    ^^^^^^"
+	if err.Error() != want {
+		t.Errorf("got\n%s\nwant\n%s", err, want)
+	}
+}
+
+func TestNewFromBookmark(t *testing.T) {
+	const src = "a = 1\nb =\tf(a)"
+
+	b := NewBookmark(src, 10, 14, 7)
+	if b.Offset != 7 || b.Pos != 10 || b.End != 14 || b.LineNo != 2 || b.Column != 5 || b.Line != "b =\tf(a)" {
+		t.Errorf("NewBookmark = %+v", b)
+	}
+
+	err := NewFromBookmark("test.ptk", b, "failed")
+	want := "patukek! error in file test.ptk at line 2:\n    b =    f(a)\n           ^^^^\nfailed"
+	if err.Error() != want {
+		t.Errorf("got\n%s\nwant\n%s", err, want)
+	}
+
+	// Code without a bookmark, such as a builtin, has no location.
+	err = NewFromBookmark("test.ptk", Bookmark{}, "failed %d", 1)
+	if _, ok := err.(*Error); ok || err.Error() != "failed 1" {
+		t.Errorf("got %#v, want a plain error", err)
+	}
+}
+
+func TestNewWithTrace(t *testing.T) {
+	const src = "f = patukek(n) {\n\tf(n + 1)\n}\ng = patukek() { f(0) }\ng()"
+
+	var (
+		inner = NewBookmark(src, 19, 27, 0)
+		outer = NewBookmark(src, 46, 49, 0)
+		top   = NewBookmark(src, 53, 56, 0)
+	)
+
+	tests := []struct {
+		name  string
+		trace []Bookmark
+		want  string
+	}{
+		{"no trace", nil, "stack overflow"},
+		{
+			"distinct calls",
+			[]Bookmark{inner, outer, top},
+			"stack overflow\ncalled from:\n" +
+				"    test.ptk:2: f(n + 1)\n" +
+				"    test.ptk:4: g = patukek() { f(0) }\n" +
+				"    test.ptk:5: g()",
+		},
+		{
+			"recursion",
+			[]Bookmark{inner, inner, inner, outer, top},
+			"stack overflow\ncalled from:\n" +
+				"    test.ptk:2: f(n + 1)\n" +
+				"    ... 2 more\n" +
+				"    test.ptk:4: g = patukek() { f(0) }\n" +
+				"    test.ptk:5: g()",
+		},
+	}
+
+	for _, tt := range tests {
+		err := NewWithTrace("test.ptk", inner, tt.trace, "stack overflow")
+		if got := err.Error(); !strings.HasSuffix(got, "\n"+tt.want) {
+			t.Errorf("%s: got\n%s\nwant it to end with\n%s", tt.name, got, tt.want)
+		}
+	}
+
+	var trace []Bookmark
+	for i := 0; i < 100; i++ {
+		trace = append(trace, inner, outer)
+	}
+	_, calls, _ := strings.Cut(NewWithTrace("test.ptk", inner, trace, "failed").Error(), "called from:\n")
+	lines := strings.Split(calls, "\n")
+	if len(lines) != maxTrace+1 {
+		t.Errorf("a trace of %d calls takes %d lines, want %d", len(trace), len(lines), maxTrace+1)
+	}
+	if last, want := lines[len(lines)-1], "    ... 180 more"; last != want {
+		t.Errorf("the trace ends with %q, want %q", last, want)
+	}
+}
@@ -0,0 +1,49 @@
+package patukek_err
+
+import "sort"
+
+// Position is a location in a source file. Line and Column start at 1
+// and Column counts bytes, so that tabs and wide characters do not
+// depend on the editor.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Span is a half-open range of a source file.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Lines converts byte offsets of a source file to positions.
+type Lines struct {
+	src    string
+	starts []int
+}
+
+func NewLines(src string) *Lines {
+	l := &Lines{src: src, starts: []int{0}}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			l.starts = append(l.starts, i+1)
+		}
+	}
+	return l
+}
+
+func (l *Lines) Position(offset int) Position {
+	offset = min(max(offset, 0), len(l.src))
+
+	i := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+	return Position{
+		Offset: offset,
+		Line:   i + 1,
+		Column: offset - l.starts[i] + 1,
+	}
+}
+
+func (l *Lines) Span(start, end int) Span {
+	return Span{Start: l.Position(start), End: l.Position(max(start, end))}
+}
@@ -0,0 +1,50 @@
+package patukek_err
+
+import "testing"
+
+func TestPosition(t *testing.T) {
+	const src = "ab\ncd\n\nмир x"
+
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{0, Position{0, 1, 1}},
+		{2, Position{2, 1, 3}}, // the newline ends its line
+		{3, Position{3, 2, 1}},
+		{6, Position{6, 3, 1}}, // an empty line
+		{7, Position{7, 4, 1}},
+		{14, Position{14, 4, 8}}, // columns count bytes
+		{len(src), Position{len(src), 4, 9}},
+		{-1, Position{0, 1, 1}},
+		{100, Position{len(src), 4, 9}},
+	}
+
+	l := NewLines(src)
+	for _, tt := range tests {
+		if got := l.Position(tt.offset); got != tt.want {
+			t.Errorf("Position(%d) = %+v, want %+v", tt.offset, got, tt.want)
+		}
+	}
+
+	if got, want := NewLines("").Position(0), (Position{0, 1, 1}); got != want {
+		t.Errorf("Position(0) of an empty file = %+v, want %+v", got, want)
+	}
+	if got, want := NewLines("a\n").Position(2), (Position{2, 2, 1}); got != want {
+		t.Errorf("Position at EOF after a newline = %+v, want %+v", got, want)
+	}
+}
+
+func TestSpan(t *testing.T) {
+	l := NewLines("f(1,\n  2)")
+
+	want := Span{Start: Position{0, 1, 1}, End: Position{9, 2, 5}}
+	if got := l.Span(0, 9); got != want {
+		t.Errorf("Span(0, 9) = %+v, want %+v", got, want)
+	}
+
+	want = Span{Start: Position{3, 1, 4}, End: Position{3, 1, 4}}
+	if got := l.Span(3, 1); got != want {
+		t.Errorf("Span(3, 1) = %+v, want %+v", got, want)
+	}
+}
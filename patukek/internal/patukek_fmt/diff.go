@@ -0,0 +1,149 @@
+package patukek_fmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type edit struct {
+	op   byte
+	line string
+}
+
+// Diff returns a unified diff from old to new, or nil if they are equal.
+func Diff(name string, old, new []byte) []byte {
+	if string(old) == string(new) {
+		return nil
+	}
+
+	var (
+		a     = splitLines(string(old))
+		b     = splitLines(string(new))
+		edits = diffLines(a, b)
+		buf   strings.Builder
+	)
+
+	fmt.Fprintf(&buf, "--- %s.orig\n+++ %s\n", name, name)
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the changes are separated by less than
+		// twice the context.
+		start := max(i-diffContext, 0)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(end+diffContext, len(edits))
+
+		aStart, bStart := lineNumbers(edits, start)
+		aLen, bLen := 0, 0
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				aLen++
+			}
+			if e.op != '-' {
+				bLen++
+			}
+		}
+
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, e := range edits[start:end] {
+			fmt.Fprintf(&buf, "%c%s\n", e.op, e.line)
+		}
+		i = end
+	}
+	return []byte(buf.String())
+}
+
+// splitLines splits s into lines, marking an unterminated last line the
+// way diff does so that a missing final newline shows up as a change.
+func splitLines(s string) []string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
+
+// lineNumbers returns the 1-based lines of both inputs at edits[i].
+func lineNumbers(edits []edit, i int) (int, int) {
+	a, b := 1, 1
+	for _, e := range edits[:i] {
+		if e.op != '+' {
+			a++
+		}
+		if e.op != '-' {
+			b++
+		}
+	}
+	return a, b
+}
+
+// diffLines computes the edit script turning a into b using the longest
+// common subsequence of lines, after trimming the common prefix and suffix.
+func diffLines(a, b []string) []edit {
+	var pre, suf int
+
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var (
+		ma  = a[pre : len(a)-suf]
+		mb  = b[pre : len(b)-suf]
+		lcs = make([][]int, len(ma)+1)
+		ret []edit
+	)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	for _, l := range a[:pre] {
+		ret = append(ret, edit{' ', l})
+	}
+
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			ret = append(ret, edit{' ', ma[i]})
+			i++
+			j++
+		case j == len(mb) || (i < len(ma) && lcs[i+1][j] >= lcs[i][j+1]):
+			ret = append(ret, edit{'-', ma[i]})
+			i++
+		default:
+			ret = append(ret, edit{'+', mb[j]})
+			j++
+		}
+	}
+
+	for _, l := range a[len(a)-suf:] {
+		ret = append(ret, edit{' ', l})
+	}
+	return ret
+}
@@ -0,0 +1,44 @@
+package patukek_fmt
+
+import (
+	"errors"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_item"
+	"patukek/internal/patukek_lexer"
+	"patukek/internal/patukek_parser"
+)
+
+// Format parses src and prints it back in canonical form, keeping all of
+// its comments. Formatting already formatted source returns it unchanged.
+func Format(file, src string) ([]byte, error) {
+	tree, errs := patukek_parser.Parse(file, src)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	block, ok := tree.(*patukek_ast.Block)
+	if !ok {
+		return nil, errors.New("patukek_fmt: unexpected program node")
+	}
+
+	p := printer{
+		src:      src,
+		comments: comments(src),
+	}
+	p.stmts(block)
+	return []byte(p.buf.String()), nil
+}
+
+func comments(src string) []patukek_item.Item {
+	var ret []patukek_item.Item
+
+	for _, i := range patukek_lexer.Lex(src) {
+		if i.Is(patukek_item.Comment) || i.Is(patukek_item.DocComment) {
+			i.Val = strings.TrimRight(i.Val, " \t\r")
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,88 @@
+package patukek_fmt
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files of testdata")
+
+// TestGolden formats every testdata/*.input file and compares the result
+// with the .golden file next to it.
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.input")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range files {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Format(path, string(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := strings.TrimSuffix(path, ".input") + ".golden"
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("formatting %s differs from %s:\n%s", path, golden, Diff(golden, want, got))
+			}
+		})
+	}
+}
+
+// TestIdempotent checks that formatted sources, the golden files and the
+// examples, come out of Format unchanged.
+func TestIdempotent(t *testing.T) {
+	golden, err := filepath.Glob("testdata/*.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples, err := filepath.Glob("../../examples/*.ptk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range append(golden, examples...) {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			once, err := Format(path, string(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			twice, err := Format(path, string(once))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(once) != string(twice) {
+				t.Errorf("formatting %s again changes it:\n%s", path, Diff(path, once, twice))
+			}
+			if strings.HasSuffix(path, ".golden") && string(once) != string(src) {
+				t.Errorf("formatting %s changes it:\n%s", path, Diff(path, src, once))
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package patukek_fmt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_ast/calc_ops"
+	"patukek/internal/patukek_item"
+)
+
+// Binding strength of the printed expressions, mirroring the parser's
+// precedences. Operands binding looser than their context get parentheses.
+const (
+	lowest int = iota
+	assignment
+	logicalOr
+	logicalAnd
+	equality
+	relational
+	additive
+	multiplicative
+	prefix
+	postfix
+	atom
+)
+
+var opPrecedences = map[string]int{
+	"||": logicalOr,
+	"&&": logicalAnd,
+	"==": equality,
+	"!=": equality,
+	"<":  relational,
+	">":  relational,
+	"<=": relational,
+	">=": relational,
+	"+":  additive,
+	"-":  additive,
+	"*":  multiplicative,
+	"/":  multiplicative,
+	"%":  multiplicative,
+}
+
+type printer struct {
+	src      string
+	comments []patukek_item.Item
+	buf      strings.Builder
+	indent   int
+}
+
+func (p *printer) write(a ...string) {
+	for _, s := range a {
+		p.buf.WriteString(s)
+	}
+}
+
+func (p *printer) newline() {
+	p.write("\n", strings.Repeat("\t", p.indent))
+}
+
+// stmts prints the top-level statements of a program.
+func (p *printer) stmts(b *patukek_ast.Block) {
+	for _, n := range b.Nodes {
+		p.leadingComments(n.Pos())
+		p.separate(n.Pos())
+		p.stmt(n)
+	}
+	p.leadingComments(b.End())
+
+	if p.buf.Len() > 0 {
+		p.write("\n")
+	}
+}
+
+func (p *printer) block(n patukek_ast.Node) {
+	b := n.(*patukek_ast.Block)
+
+	if len(b.Nodes) == 0 && !p.hasComments(b.End()) {
+		p.write("{}")
+		return
+	}
+
+	if p.isInline(b) {
+		p.write("{ ")
+		p.stmt(b.Nodes[0])
+		p.write(" }")
+		return
+	}
+
+	p.write("{")
+	p.indent++
+	for _, s := range b.Nodes {
+		p.leadingComments(s.Pos())
+		p.separate(s.Pos())
+		p.stmt(s)
+	}
+	p.leadingComments(b.End())
+	p.indent--
+	p.newline()
+	p.write("}")
+}
+
+// isInline reports whether b is kept on a single line: it must have been
+// written on one line and hold a single statement without nested blocks
+// or comments.
+func (p *printer) isInline(b *patukek_ast.Block) bool {
+	return len(b.Nodes) == 1 &&
+		!strings.Contains(p.src[b.Pos():b.End()], "\n") &&
+		!p.hasComments(b.End()) &&
+		!hasBlock(b.Nodes[0])
+}
+
+func (p *printer) hasComments(end int) bool {
+	return len(p.comments) > 0 && p.comments[0].Pos < end
+}
+
+// separate starts a new line for a statement or comment at pos, keeping
+// at most one blank line from the source before it.
+func (p *printer) separate(pos int) {
+	out := p.buf.String()
+	if out == "" {
+		p.write(strings.Repeat("\t", p.indent))
+		return
+	}
+
+	if !strings.HasSuffix(out, "{") && p.blankBefore(pos) {
+		p.write("\n")
+	}
+	p.newline()
+}
+
+func (p *printer) blankBefore(pos int) bool {
+	var n int
+
+	for i := pos - 1; i >= 0; i-- {
+		switch p.src[i] {
+		case '\n':
+			n++
+		case ' ', '\t', '\r':
+		default:
+			return n > 1
+		}
+	}
+	return false
+}
+
+// leadingComments prints the pending comments that start before end.
+// Comments that shared a line with the preceding code stay at the end of
+// that line, the others go on lines of their own.
+func (p *printer) leadingComments(end int) {
+	for p.hasComments(end) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+
+		if p.buf.Len() > 0 && p.isTrailing(c.Pos) {
+			p.write(" ", c.Val)
+		} else {
+			p.separate(c.Pos)
+			p.write(c.Val)
+		}
+	}
+}
+
+func (p *printer) isTrailing(pos int) bool {
+	for i := pos - 1; i >= 0; i-- {
+		switch p.src[i] {
+		case '\n':
+			return false
+		case ' ', '\t', '\r':
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func (p *printer) stmt(n patukek_ast.Node) {
+	if r, ok := n.(patukek_ast.Return); ok {
+		p.write("return")
+		if v := r.Value(); v != nil {
+			p.write(" ")
+			p.expr(v, lowest)
+		}
+		return
+	}
+	p.expr(n, lowest)
+}
+
+// expr prints n, in parentheses if it binds looser than prec.
+func (p *printer) expr(n patukek_ast.Node, prec int) {
+	if precedence(n) < prec {
+		p.write("(")
+		defer p.write(")")
+	}
+
+	switch n := n.(type) {
+	case patukek_ast.Identifier:
+		p.ident(n)
+
+	case patukek_ast.Integer, patukek_ast.Float:
+		p.write(n.String())
+
+	case patukek_ast.String:
+		p.write(`"`, n.Raw(), `"`)
+
+	case patukek_ast.List:
+		p.write("[")
+		p.exprList(n.Elems(), n.End())
+		p.write("]")
+
+	case patukek_ast.Assign:
+		p.expr(n.Left(), assignment+1)
+		p.write(" = ")
+		p.expr(n.Right(), assignment)
+
+	case patukek_ast.BinaryOp:
+		l, r := n.Operands()
+		prec := opPrecedences[n.Op()]
+		p.expr(l, prec)
+		p.write(" ", n.Op(), " ")
+		p.expr(r, prec+1)
+
+	case calc_ops.Negative:
+		p.write("-")
+		p.expr(n.Operand(), prefix)
+
+	case patukek_ast.Call:
+		p.expr(n.Fn, postfix)
+		p.write("(")
+		p.exprList(n.Args, n.End())
+		p.write(")")
+
+	case patukek_ast.Dot:
+		p.expr(n.Left(), postfix)
+		p.write(".", n.Name())
+
+	case patukek_ast.Function:
+		p.write("patukek(")
+		for i, param := range n.Params() {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.ident(param)
+		}
+		p.write(") ")
+		if n.Result != nil {
+			p.write("-> ", n.Result.String(), " ")
+		}
+		p.block(n.Body())
+
+	case patukek_ast.IfExpr:
+		p.write("if ")
+		p.expr(n.Cond(), lowest)
+		p.write(" ")
+		p.block(n.Body())
+
+		switch alt := n.Else().(type) {
+		case nil:
+		case patukek_ast.IfExpr:
+			p.write(" else ")
+			p.expr(alt, lowest)
+		default:
+			p.write(" else ")
+			p.block(alt)
+		}
+
+	default:
+		panic(fmt.Sprintf("patukek_fmt: unexpected node %T", n))
+	}
+}
+
+// ident prints an identifier with its type annotation, if any.
+func (p *printer) ident(i patukek_ast.Identifier) {
+	p.write(i.String())
+	if i.Type != nil {
+		p.write(": ", i.Type.String())
+	}
+}
+
+// exprList prints the elements of a list or the arguments of a call that
+// ends at end. When comments stand between the elements, each element goes
+// on a line of its own so the comments stay next to it.
+func (p *printer) exprList(nodes []patukek_ast.Node, end int) {
+	if !p.hasCommentsBetween(nodes, end) {
+		for i, n := range nodes {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expr(n, lowest)
+		}
+		return
+	}
+
+	p.indent++
+	for i, n := range nodes {
+		p.leadingComments(n.Pos())
+		p.newline()
+		p.expr(n, lowest)
+		if i < len(nodes)-1 {
+			p.write(",")
+		}
+	}
+	p.leadingComments(end)
+	p.indent--
+	p.newline()
+}
+
+// hasCommentsBetween reports whether a pending comment before end lies
+// outside of nodes.
+func (p *printer) hasCommentsBetween(nodes []patukek_ast.Node, end int) bool {
+	for _, c := range p.comments {
+		if c.Pos >= end {
+			return false
+		}
+		if !slices.ContainsFunc(nodes, func(n patukek_ast.Node) bool {
+			return n.Pos() <= c.Pos && c.Pos < n.End()
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+func precedence(n patukek_ast.Node) int {
+	switch n := n.(type) {
+	case patukek_ast.Assign:
+		return assignment
+	case patukek_ast.BinaryOp:
+		return opPrecedences[n.Op()]
+	case calc_ops.Negative:
+		return prefix
+	case patukek_ast.Call, patukek_ast.Dot:
+		return postfix
+	default:
+		return atom
+	}
+}
+
+// hasBlock reports whether n contains a function or an if expression.
+func hasBlock(n patukek_ast.Node) bool {
+	switch n := n.(type) {
+	case patukek_ast.Function, patukek_ast.IfExpr:
+		return true
+	case patukek_ast.Return:
+		return n.Value() != nil && hasBlock(n.Value())
+	case patukek_ast.Assign:
+		return hasBlock(n.Left()) || hasBlock(n.Right())
+	case patukek_ast.BinaryOp:
+		l, r := n.Operands()
+		return hasBlock(l) || hasBlock(r)
+	case calc_ops.Negative:
+		return hasBlock(n.Operand())
+	case patukek_ast.Dot:
+		return hasBlock(n.Left())
+	case patukek_ast.Call:
+		return hasBlock(n.Fn) || anyHasBlock(n.Args)
+	case patukek_ast.List:
+		return anyHasBlock(n.Elems())
+	default:
+		return false
+	}
+}
+
+func anyHasBlock(nodes []patukek_ast.Node) bool {
+	for _, n := range nodes {
+		if hasBlock(n) {
+			return true
+		}
+	}
+	return false
+}
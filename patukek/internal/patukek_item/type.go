@@ -8,6 +8,7 @@ const (
 	Null
 	Ident
 	Int
+	Float
 	String
 	Assign
 	Plus
@@ -25,6 +26,8 @@ const (
 	And
 	Or
 	Comma
+	Colon
+	Arrow
 	Semicolon
 	NewLine
 	LParen
@@ -33,58 +36,68 @@ const (
 	RBrace
 	LBracket
 	RBracket
+	Dot
 	Function
 	If
 	Else
 	True
 	False
 	Return
+	Comment
+	DocComment
 )
 
 var typemap = map[Type]string{
-	EOF:       "eof",
-	Error:     "error",
-	Null:      "null",
-	Ident:     "IDENT",
-	Int:       "int",
-	String:    "string",
-	Assign:    "=",
-	Plus:      "+",
-	Minus:     "*",
-	Slash:     "/",
-	Asterisk:  "*",
-	Modulus:   "%",
-	Equals:    "==",
-	NotEquals: "!=",
-	LT:        "<",
-	GT:        ">",
-	LTEQ:      "<=",
-	GTEQ:      ">=",
-	And:       "&&",
-	Or:        "||",
-	Semicolon: ";",
-	NewLine:   "new line",
-	LParen:    "(",
-	RParen:    ")",
-	LBrace:    "{",
-	RBrace:    "}",
-	LBracket:  "[",
-	RBracket:  "]",
-	Function:  "function",
-	If:        "if",
-	Else:      "else",
-	True:      "true",
-	False:     "false",
+	EOF:        "eof",
+	Error:      "error",
+	Null:       "null",
+	Ident:      "IDENT",
+	Int:        "int",
+	Float:      "float",
+	String:     "string",
+	Assign:     "=",
+	Plus:       "+",
+	Minus:      "*",
+	Slash:      "/",
+	Asterisk:   "*",
+	Modulus:    "%",
+	Equals:     "==",
+	NotEquals:  "!=",
+	LT:         "<",
+	GT:         ">",
+	LTEQ:       "<=",
+	GTEQ:       ">=",
+	And:        "&&",
+	Or:         "||",
+	Comma:      ",",
+	Colon:      ":",
+	Arrow:      "->",
+	Semicolon:  ";",
+	NewLine:    "new line",
+	LParen:     "(",
+	RParen:     ")",
+	LBrace:     "{",
+	RBrace:     "}",
+	LBracket:   "[",
+	RBracket:   "]",
+	Dot:        ".",
+	Function:   "function",
+	If:         "if",
+	Else:       "else",
+	True:       "true",
+	False:      "false",
+	Comment:    "comment",
+	DocComment: "doc comment",
 }
 
 var keywords = map[string]Type{
-	"patukek":  Function,
-	"if":     If,
-	"else":   Else,
-	"true":   True,
-	"false":  False,
-	"return": Return,
-	"null":   Null,
+	"patukek": Function,
+	"if":      If,
+	"else":    Else,
+	"true":    True,
+	"false":   False,
+	"return":  Return,
+	"null":    Null,
 }
 
 func (t Type) String() string {
@@ -96,4 +109,4 @@ func Lookup(ident string) Type {
 		return t
 	}
 	return Ident
-}
\ No newline at end of file
+}
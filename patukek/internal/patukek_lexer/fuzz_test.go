@@ -0,0 +1,20 @@
+package patukek_lexer
+
+import (
+	"testing"
+
+	"patukek/internal/patukek_seed"
+)
+
+func FuzzLex(f *testing.F) {
+	patukek_seed.AddExamples(f)
+	f.Add(`s = "a{b}c" /* x /* y */ */ 1.5e3 // z`)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		for _, i := range Lex(in) {
+			if i.Pos < 0 || i.Pos > len(in) {
+				t.Fatalf("item %v at %d is outside the input of length %d", i, i.Pos, len(in))
+			}
+		}
+	})
+}
@@ -9,19 +9,24 @@ import (
 	"patukek/internal/patukek_item"
 )
 
-type lexer struct {
-	items chan patukek_item.Item
+// Lexer splits its input into items on demand. Every call to NextToken
+// runs the state functions until they have emitted an item.
+type Lexer struct {
+	items []patukek_item.Item
+	head  int
+	state stateFn
 	input string
 	start int
 	pos   int
 	width int
+	last  patukek_item.Type
 }
 
-type stateFn func(*lexer) stateFn
+type stateFn func(*Lexer) stateFn
 
 const eof = -1
 
-func (l *lexer) next() rune {
+func (l *Lexer) next() rune {
 	var r rune
 	if l.pos >= len(l.input) {
 		l.width = 0
@@ -32,15 +37,15 @@ func (l *lexer) next() rune {
 	return r
 }
 
-func (l *lexer) ignore() {
+func (l *Lexer) ignore() {
 	l.start = l.pos
 }
 
-func (l *lexer) backup() {
+func (l *Lexer) backup() {
 	l.pos -= l.width
 }
 
-func (l *lexer) accept(valid string) bool {
+func (l *Lexer) accept(valid string) bool {
 	if strings.IndexRune(valid, l.next()) >= 0 {
 		return true
 	}
@@ -48,7 +53,7 @@ func (l *lexer) accept(valid string) bool {
 	return false
 }
 
-func (l *lexer) acceptRun(valid string) bool {
+func (l *Lexer) acceptRun(valid string) bool {
 	for strings.IndexRune(valid, l.next()) >= 0 {
 
 	}
@@ -56,61 +61,84 @@ func (l *lexer) acceptRun(valid string) bool {
 	return true
 }
 
-func (l *lexer) emit(t patukek_item.Type) {
-	l.items <- patukek_item.Item{
+func (l *Lexer) emit(t patukek_item.Type) {
+	l.items = append(l.items, patukek_item.Item{
 		Typ: t,
 		Val: l.input[l.start:l.pos],
 		Pos: l.start,
-	}
+	})
 	l.start = l.pos
+
+	// Comments are transparent to newline handling.
+	if t != patukek_item.Comment && t != patukek_item.DocComment {
+		l.last = t
+	}
+}
+
+func (l *Lexer) ignoreLine() {
+	for r := l.next(); r != '\n' && r != eof; r = l.next() {
+	}
+	l.backup()
 }
 
-func (l *lexer) current() string {
+func (l *Lexer) current() string {
 	return l.input[l.start:l.pos]
 }
 
-func (l *lexer) ignoreSpaces() {
+func (l *Lexer) ignoreSpaces() {
 	l.acceptRun(" \n\t\r")
 	l.ignore()
 }
 
-func (l *lexer) errorf(format string, args ...any) {
-	l.items <- patukek_item.Item{
+func (l *Lexer) errorf(format string, args ...any) {
+	l.items = append(l.items, patukek_item.Item{
 		Typ: patukek_item.Error,
 		Val: fmt.Sprintf(format, args...),
 		Pos: l.start,
-	}
+	})
 	l.start = l.pos
 }
 
-func (l *lexer) run() {
-	l.ignoreSpaces()
-	for state := lexExpression; state != nil; {
-		state = state(l)
+// NextToken returns the next item of the input. Once the input is
+// exhausted, it keeps returning EOF.
+func (l *Lexer) NextToken() patukek_item.Item {
+	for l.head == len(l.items) {
+		if l.state == nil {
+			return patukek_item.Item{Typ: patukek_item.EOF, Pos: len(l.input)}
+		}
+		l.items, l.head = l.items[:0], 0
+		l.state = l.state(l)
 	}
-	close(l.items)
+
+	i := l.items[l.head]
+	l.head++
+	return i
 }
 
-func lexIdentifier(l *lexer) stateFn {
-	var chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
-	if l.acceptRun(chars) {
-		l.emit(patukek_item.Lookup(l.current()))
+func lexIdentifier(l *Lexer) stateFn {
+	for r := l.next(); isLetter(r) || unicode.IsDigit(r); r = l.next() {
 	}
+	l.backup()
+	l.emit(patukek_item.Lookup(l.current()))
 	return lexExpression
 }
 
-func lexNumber(l *lexer) stateFn {
+func lexNumber(l *Lexer) stateFn {
 	var typ = patukek_item.Int
 	var digits = "0123456789"
 
 	l.accept("+-")
 
 	l.acceptRun(digits)
+	if l.accept(".") {
+		typ = patukek_item.Float
+		l.acceptRun(digits)
+	}
 	l.emit(typ)
 	return lexExpression
 }
 
-func lexString(l *lexer) stateFn {
+func lexString(l *Lexer) stateFn {
 Loop:
 	for {
 		switch l.next() {
@@ -130,42 +158,87 @@ Loop:
 	return lexExpression
 }
 
-func lexPlus(l *lexer) stateFn {
+func lexPlus(l *Lexer) stateFn {
 	l.next()
 	l.backup()
 	l.emit(patukek_item.Plus)
 	return lexExpression
 }
 
-func lexMinus(l *lexer) stateFn {
-	l.next()
+func lexMinus(l *Lexer) stateFn {
+	if l.next() == '>' {
+		l.emit(patukek_item.Arrow)
+		return lexExpression
+	}
 	l.backup()
 	l.emit(patukek_item.Minus)
 	return lexExpression
 }
 
-func lexTimes(l *lexer) stateFn {
+func lexTimes(l *Lexer) stateFn {
 	l.next()
 	l.backup()
 	l.emit(patukek_item.Asterisk)
 	return lexExpression
 }
 
-func lexSlash(l *lexer) stateFn {
-	l.next()
-	l.backup()
-	l.emit(patukek_item.Slash)
+func lexSlash(l *Lexer) stateFn {
+	switch l.next() {
+	case '/':
+		return lexLineComment
+
+	case '*':
+		return lexBlockComment
+
+	default:
+		l.backup()
+		l.emit(patukek_item.Slash)
+		return lexExpression
+	}
+}
+
+func lexLineComment(l *Lexer) stateFn {
+	isDoc := strings.HasPrefix(l.input[l.pos:], "/") && !strings.HasPrefix(l.input[l.pos:], "//")
+
+	l.ignoreLine()
+	if isDoc {
+		l.emit(patukek_item.DocComment)
+	} else {
+		l.emit(patukek_item.Comment)
+	}
+	return lexExpression
+}
+
+func lexBlockComment(l *Lexer) stateFn {
+	for depth := 1; depth > 0; {
+		switch l.next() {
+		case eof:
+			l.errorf("unterminated block comment")
+			return nil
+
+		case '/':
+			if l.accept("*") {
+				depth++
+			}
+
+		case '*':
+			if l.accept("/") {
+				depth--
+			}
+		}
+	}
+	l.emit(patukek_item.Comment)
 	return lexExpression
 }
 
-func lexMod(l *lexer) stateFn {
+func lexMod(l *Lexer) stateFn {
 	l.next()
 	l.backup()
 	l.emit(patukek_item.Modulus)
 	return lexExpression
 }
 
-func lexExpression(l *lexer) stateFn {
+func lexExpression(l *Lexer) stateFn {
 	switch r := l.next(); {
 
 	case isSpace(r):
@@ -176,9 +249,15 @@ func lexExpression(l *lexer) stateFn {
 		return lexIdentifier
 
 	case r == '\n':
-		l.emit(patukek_item.Semicolon)
+		if l.newlineEndsStatement() {
+			l.emit(patukek_item.Semicolon)
+		}
 		l.ignoreSpaces()
 
+	case r == '#':
+		l.ignoreLine()
+		l.emit(patukek_item.Comment)
+
 	case r == '"':
 		l.ignore()
 		return lexString
@@ -197,10 +276,16 @@ func lexExpression(l *lexer) stateFn {
 	case r == ']':
 		l.emit(patukek_item.RBracket)
 
+	case r == '.':
+		l.emit(patukek_item.Dot)
+
 	case r == ',':
 		l.emit(patukek_item.Comma)
 		l.ignoreSpaces()
 
+	case r == ':':
+		l.emit(patukek_item.Colon)
+
 	case r == '{':
 		l.emit(patukek_item.LBrace)
 		l.ignoreSpaces()
@@ -234,6 +319,9 @@ func lexExpression(l *lexer) stateFn {
 	case r == '!':
 		if l.next() == '=' {
 			l.emit(patukek_item.NotEquals)
+		} else {
+			l.backup()
+			l.errorf("patukek_lexer: invalid patukek_item %q", r)
 		}
 
 	case r == '<':
@@ -258,12 +346,18 @@ func lexExpression(l *lexer) stateFn {
 		next := l.next()
 		if next == '&' {
 			l.emit(patukek_item.And)
+		} else {
+			l.backup()
+			l.errorf("patukek_lexer: invalid patukek_item %q", r)
 		}
 
 	case r == '|':
 		next := l.next()
 		if next == '|' {
 			l.emit(patukek_item.Or)
+		} else {
+			l.backup()
+			l.errorf("patukek_lexer: invalid patukek_item %q", r)
 		}
 
 	case r == eof:
@@ -280,6 +374,19 @@ func lexExpression(l *lexer) stateFn {
 	return lexExpression
 }
 
+// newlineEndsStatement reports whether a newline after the last emitted
+// item terminates a statement. ignoreSpaces already skips the newlines
+// after an opening bracket, a comma or a terminator; this only matters when
+// a comment stands between them, as in "[1, // one" or a comment-only line.
+func (l *Lexer) newlineEndsStatement() bool {
+	switch l.last {
+	case patukek_item.Semicolon, patukek_item.LParen, patukek_item.LBracket, patukek_item.LBrace, patukek_item.Comma:
+		return false
+	default:
+		return true
+	}
+}
+
 func isLetter(r rune) bool {
 	return r == '_' || unicode.IsLetter(r)
 }
@@ -289,14 +396,31 @@ func isSpace(r rune) bool {
 }
 
 func isNumber(r rune) bool {
-	return r == '+' || r == '-' || unicode.IsNumber(r)
+	return r == '+' || r == '-' || '0' <= r && r <= '9'
 }
 
-func Lex(in string) chan patukek_item.Item {
-	l := &lexer{
+func New(in string) *Lexer {
+	l := &Lexer{
 		input: in,
-		items: make(chan patukek_item.Item),
+		last:  patukek_item.Semicolon,
+		state: lexExpression,
+	}
+	l.ignoreSpaces()
+	return l
+}
+
+// Lex returns all the items of in, up to and including EOF.
+func Lex(in string) []patukek_item.Item {
+	var (
+		l   = New(in)
+		ret []patukek_item.Item
+	)
+
+	for {
+		i := l.NextToken()
+		ret = append(ret, i)
+		if i.Is(patukek_item.EOF) {
+			return ret
+		}
 	}
-	go l.run()
-	return l.items
-}
\ No newline at end of file
+}
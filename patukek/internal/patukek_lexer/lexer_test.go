@@ -0,0 +1,81 @@
+package patukek_lexer
+
+import (
+	"slices"
+	"testing"
+
+	"patukek/internal/patukek_item"
+)
+
+func types(in string) []patukek_item.Type {
+	var ret []patukek_item.Type
+	for _, i := range Lex(in) {
+		ret = append(ret, i.Typ)
+	}
+	return ret
+}
+
+func TestNewlines(t *testing.T) {
+	const (
+		ident  = patukek_item.Ident
+		intT   = patukek_item.Int
+		semi   = patukek_item.Semicolon
+		comma  = patukek_item.Comma
+		cmt    = patukek_item.Comment
+		doc    = patukek_item.DocComment
+		lbrack = patukek_item.LBracket
+		rbrack = patukek_item.RBracket
+		lparen = patukek_item.LParen
+		rparen = patukek_item.RParen
+		eof    = patukek_item.EOF
+	)
+
+	tests := []struct {
+		name string
+		in   string
+		want []patukek_item.Type
+	}{
+		{"statements", "a\nb", []patukek_item.Type{ident, semi, ident, eof}},
+		{"blank lines", "a\n\n\nb", []patukek_item.Type{ident, semi, ident, eof}},
+		{"after a comma", "f(a,\nb)", []patukek_item.Type{ident, lparen, ident, comma, ident, rparen, eof}},
+		{"after a bracket", "[\n1]", []patukek_item.Type{lbrack, intT, rbrack, eof}},
+		{"before a bracket", "[1\n]", []patukek_item.Type{lbrack, intT, semi, rbrack, eof}},
+		{"trailing comment", "a // x\nb", []patukek_item.Type{ident, cmt, semi, ident, eof}},
+		{"comment after a comma", "[1, // one\n2]", []patukek_item.Type{lbrack, intT, comma, cmt, intT, rbrack, eof}},
+		{"comment after a bracket", "[ # list\n1]", []patukek_item.Type{lbrack, cmt, intT, rbrack, eof}},
+		{"comment-only line", "a\n// x\nb", []patukek_item.Type{ident, semi, cmt, ident, eof}},
+		{"block comment", "a /* x\ny */\nb", []patukek_item.Type{ident, cmt, semi, ident, eof}},
+		{"doc comment", "/// doc\na", []patukek_item.Type{doc, ident, eof}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := types(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("Lex(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"// line", "// line"},
+		{"# hash", "# hash"},
+		{"/// doc", "/// doc"},
+		{"/* a /* nested */ b */", "/* a /* nested */ b */"},
+	}
+
+	for _, tt := range tests {
+		items := Lex(tt.in)
+		if len(items) != 2 || items[0].Val != tt.want {
+			t.Errorf("Lex(%q) = %v, want a comment %q", tt.in, items, tt.want)
+		}
+	}
+
+	if items := Lex("/* a /* b */"); items[0].Typ != patukek_item.Error {
+		t.Errorf("unterminated block comment lexed as %v", items)
+	}
+}
@@ -0,0 +1,298 @@
+package patukek_lsp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_build"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_err"
+	"patukek/internal/patukek_item"
+	"patukek/internal/patukek_lexer"
+	"patukek/internal/patukek_obj"
+	"patukek/internal/patukek_types"
+)
+
+// document is an open text document and what the parser and the compiler
+// found out about it.
+type document struct {
+	uri     string
+	text    string
+	errs    []error
+	refs    []patukek_compiler.Reference
+	assigns map[int]patukek_ast.Assign
+	params  map[int]bool
+	scopes  []*scope
+	info    patukek_types.Info
+	prev    *document
+}
+
+// scope is the whole document or the span of a function, with the names
+// it defines.
+type scope struct {
+	pos, end int
+	defs     []definition
+}
+
+// definition is the first definition of a name in a scope.
+type definition struct {
+	name string
+	pos  int
+}
+
+func (s *scope) define(name string, pos int) {
+	for _, d := range s.defs {
+		if d.name == name {
+			return
+		}
+	}
+	s.defs = append(s.defs, definition{name, pos})
+}
+
+func analyze(uri, text string) *document {
+	var (
+		path = filename(uri)
+		d    = &document{
+			uri:     uri,
+			text:    text,
+			assigns: make(map[int]patukek_ast.Assign),
+			params:  make(map[int]bool),
+		}
+	)
+
+	tree, errs := patukek_build.Check(path, text, &d.info)
+	d.errs = errs
+	if tree == nil {
+		return d
+	}
+	d.collect(tree, &scope{pos: 0, end: len(text)})
+
+	c := patukek_compiler.New()
+	c.TrackReferences()
+	if _, err := patukek_build.Compile(c, path, text, tree); err != nil {
+		d.errs = append(d.errs, err)
+	}
+	d.refs = c.References
+	return d
+}
+
+// collect records the assignments, parameters and scopes of n, which is
+// in scope s. Like the compiler, it gives every function a scope of its
+// own for its name, its parameters and the variables its body assigns.
+func (d *document) collect(n patukek_ast.Node, s *scope) {
+	d.scopes = append(d.scopes, s)
+
+	patukek_ast.Inspect(n, func(n patukek_ast.Node) bool {
+		switch n := n.(type) {
+		case patukek_ast.Assign:
+			if i, ok := n.Left().(patukek_ast.Identifier); ok {
+				if _, seen := d.assigns[i.Pos()]; !seen {
+					d.assigns[i.Pos()] = n
+				}
+				s.define(i.String(), i.Pos())
+			}
+
+		case patukek_ast.Function:
+			fs := &scope{pos: n.Pos(), end: n.End()}
+			if n.Name != "" {
+				fs.define(n.Name, n.NamePos)
+			}
+			for _, p := range n.Params() {
+				d.params[p.Pos()] = true
+				fs.define(p.String(), p.Pos())
+			}
+			d.collect(n.Body(), fs)
+			return false
+		}
+		return true
+	})
+}
+
+func (d *document) diagnostics() []Diagnostic {
+	var ret = []Diagnostic{}
+
+	for _, err := range d.errs {
+		diag := Diagnostic{
+			Severity: severityError,
+			Source:   "patukek",
+			Message:  err.Error(),
+		}
+
+		var perr *patukek_err.Error
+		if errors.As(err, &perr) {
+			end := perr.End
+			if end <= perr.Pos+1 {
+				end = wordEnd(d.text, perr.Pos)
+			}
+			diag.Range = span(d.text, perr.Pos, end)
+			diag.Message = perr.Msg
+		}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
+// reference returns the identifier at offset off.
+func (d *document) reference(off int) (patukek_compiler.Reference, bool) {
+	for _, r := range d.refs {
+		if r.Pos <= off && off <= r.Pos+len(r.Name) {
+			return r, true
+		}
+	}
+	return patukek_compiler.Reference{}, false
+}
+
+func (d *document) definition(off int) (Location, bool) {
+	r, ok := d.reference(off)
+	if !ok || r.Symbol.Scope == patukek_compiler.BuiltinScope {
+		return Location{}, false
+	}
+
+	return Location{
+		URI:   d.uri,
+		Range: span(d.text, r.Symbol.Pos, r.Symbol.Pos+len(r.Name)),
+	}, true
+}
+
+func (d *document) hover(off int) (string, Range, bool) {
+	if r, ok := d.reference(off); ok {
+		rng := span(d.text, r.Pos, r.Pos+len(r.Name))
+
+		if r.Symbol.Scope == patukek_compiler.BuiltinScope {
+			for _, b := range patukek_obj.Builtins {
+				if b.Name == r.Name && b.Signature != "" {
+					return codeBlock(b.Signature), rng, true
+				}
+			}
+			return codeBlock(r.Name + " builtin"), rng, true
+		}
+
+		s := r.Name
+		if t := d.typeAt(r.Symbol.Pos); t != "" {
+			s += " " + t
+		}
+
+		if d.params[r.Symbol.Pos] {
+			return codeBlock(s + " (parameter)"), rng, true
+		}
+
+		if a, ok := d.assigns[r.Symbol.Pos]; ok {
+			doc := a.Doc
+			if f, ok := a.Right().(patukek_ast.Function); ok && doc == "" {
+				doc = f.Doc
+			}
+			if doc != "" {
+				return codeBlock(s) + "\n\n" + doc, rng, true
+			}
+			return codeBlock(s), rng, true
+		}
+		return codeBlock(s), rng, true
+	}
+
+	// Literals have no symbol; find the token under the cursor instead.
+	for _, i := range patukek_lexer.Lex(d.text) {
+		if i.Pos > off || off > i.Pos+len(i.Val) {
+			continue
+		}
+
+		switch i.Typ {
+		case patukek_item.Int:
+			return codeBlock("int"), span(d.text, i.Pos, i.Pos+len(i.Val)), true
+		case patukek_item.Float:
+			return codeBlock("float"), span(d.text, i.Pos, i.Pos+len(i.Val)), true
+		case patukek_item.String:
+			return codeBlock("string"), span(d.text, i.Pos-1, i.Pos+len(i.Val)+1), true
+		}
+	}
+	return "", Range{}, false
+}
+
+// completions returns the names visible at offset off: those defined
+// before it in the scopes it is in, then the builtins and the keywords.
+func (d *document) completions(off int) []CompletionItem {
+	var (
+		ret  []CompletionItem
+		seen = make(map[string]bool)
+	)
+
+	if d.refs == nil && d.prev != nil {
+		d = d.prev
+	}
+
+	// Scopes are in the order of their functions in the source, so inner
+	// scopes come last and their names hide those of the outer ones.
+	for i := len(d.scopes) - 1; i >= 0; i-- {
+		s := d.scopes[i]
+		if off < s.pos || off > s.end {
+			continue
+		}
+
+		for _, def := range s.defs {
+			if def.pos >= off || seen[def.name] {
+				continue
+			}
+			seen[def.name] = true
+
+			item := CompletionItem{Label: def.name, Kind: completionVariable, Detail: d.typeAt(def.pos)}
+			if _, ok := d.info.Defs[def.pos].(patukek_types.Func); ok {
+				item.Kind = completionFunction
+			}
+			ret = append(ret, item)
+		}
+	}
+
+	for _, b := range patukek_obj.Builtins {
+		if !seen[b.Name] {
+			ret = append(ret, CompletionItem{Label: b.Name, Kind: completionFunction, Detail: b.Signature})
+		}
+	}
+
+	for _, k := range []string{"patukek", "if", "else", "return"} {
+		ret = append(ret, CompletionItem{Label: k, Kind: completionKeyword})
+	}
+	return ret
+}
+
+// typeAt describes the type Check found for the variable defined at pos,
+// or returns "" if it is unknown. Functions show the names of their
+// parameters.
+func (d *document) typeAt(pos int) string {
+	t, ok := d.info.Defs[pos]
+	if !ok || t == patukek_types.Any {
+		return ""
+	}
+
+	f, ok := t.(patukek_types.Func)
+	if !ok {
+		return t.String()
+	}
+	a, ok := d.assigns[pos]
+	if !ok {
+		return t.String()
+	}
+	fn, ok := a.Right().(patukek_ast.Function)
+	if !ok {
+		return t.String()
+	}
+
+	var params []string
+	for i, p := range fn.Params() {
+		s := p.String()
+		if i < len(f.Params) && f.Params[i] != patukek_types.Any {
+			s += ": " + f.Params[i].String()
+		}
+		params = append(params, s)
+	}
+
+	s := fmt.Sprintf("patukek(%s)", strings.Join(params, ", "))
+	if f.Result != patukek_types.Any {
+		s += " -> " + f.Result.String()
+	}
+	return s
+}
+
+func codeBlock(s string) string {
+	return "```patukek\n" + s + "\n```"
+}
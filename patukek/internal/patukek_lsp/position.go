@@ -0,0 +1,86 @@
+package patukek_lsp
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// position converts a byte offset of text to an LSP position, whose
+// character counts UTF-16 code units.
+func position(text string, offset int) Position {
+	offset = min(max(offset, 0), len(text))
+
+	start := strings.LastIndexByte(text[:offset], '\n') + 1
+	return Position{
+		Line:      strings.Count(text[:start], "\n"),
+		Character: utf16Len(text[start:offset]),
+	}
+}
+
+// offset converts an LSP position to a byte offset of text.
+func offset(text string, p Position) int {
+	var i int
+
+	for line := 0; line < p.Line; line++ {
+		n := strings.IndexByte(text[i:], '\n')
+		if n < 0 {
+			return len(text)
+		}
+		i += n + 1
+	}
+
+	for units := 0; i < len(text) && text[i] != '\n'; {
+		r, w := utf8.DecodeRuneInString(text[i:])
+		units += runeLen16(r)
+		if units > p.Character {
+			break
+		}
+		i += w
+	}
+	return i
+}
+
+func runeLen16(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func utf16Len(s string) int {
+	var n int
+	for _, r := range s {
+		n += runeLen16(r)
+	}
+	return n
+}
+
+func span(text string, start, end int) Range {
+	return Range{Start: position(text, start), End: position(text, end)}
+}
+
+// wordEnd returns the end of the identifier or number starting at pos,
+// or pos+1 if there is none.
+func wordEnd(text string, pos int) int {
+	end := pos
+	for end < len(text) && isWordByte(text[end]) {
+		end++
+	}
+	if end == pos && pos < len(text) {
+		end++
+	}
+	return end
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= utf8.RuneSelf
+}
+
+// filename returns the path of a file URI, or the URI itself.
+func filename(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		return u.Path
+	}
+	return uri
+}
@@ -0,0 +1,189 @@
+package patukek_lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"patukek/internal/patukek_fmt"
+)
+
+// Server is a language server for patukek speaking JSON-RPC over a pair
+// of streams, usually stdin and stdout.
+type Server struct {
+	conn     *conn
+	docs     map[string]*document
+	shutdown bool
+}
+
+var errExit = errors.New("exit")
+
+func NewServer(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		conn: newConn(r, w),
+		docs: make(map[string]*document),
+	}
+}
+
+// Serve handles messages until the client sends exit or closes the input.
+func (s *Server) Serve() error {
+	for {
+		msg, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var rerr *responseError
+		if errors.As(err, &rerr) {
+			if err := s.conn.replyError(nil, rerr.Code, rerr.Message); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := s.handle(msg); errors.Is(err, errExit) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) error {
+	if s.shutdown && msg.Method != "exit" {
+		if msg.ID != nil {
+			return s.conn.replyError(msg.ID, codeInvalidRequest, "server is shut down")
+		}
+		return nil
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return s.conn.reply(msg.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           1,
+				"hoverProvider":              true,
+				"definitionProvider":         true,
+				"completionProvider":         map[string]any{},
+				"documentFormattingProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "patukek"},
+		})
+
+	case "initialized":
+		return nil
+
+	case "shutdown":
+		s.shutdown = true
+		return s.conn.reply(msg.ID, nil)
+
+	case "exit":
+		return errExit
+
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil
+		}
+		return s.update(p.TextDocument.URI, p.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil || len(p.ContentChanges) == 0 {
+			return nil
+		}
+		return s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil
+		}
+		delete(s.docs, p.TextDocument.URI)
+		return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         p.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/hover":
+		return s.withPosition(msg, func(d *document, off int) any {
+			text, rng, ok := d.hover(off)
+			if !ok {
+				return nil
+			}
+			return Hover{Contents: MarkupContent{Kind: "markdown", Value: text}, Range: &rng}
+		})
+
+	case "textDocument/definition":
+		return s.withPosition(msg, func(d *document, off int) any {
+			if loc, ok := d.definition(off); ok {
+				return loc
+			}
+			return nil
+		})
+
+	case "textDocument/completion":
+		return s.withPosition(msg, func(d *document, off int) any {
+			return d.completions(off)
+		})
+
+	case "textDocument/formatting":
+		var p formattingParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return s.conn.replyError(msg.ID, codeInvalidParams, err.Error())
+		}
+
+		d, ok := s.docs[p.TextDocument.URI]
+		if !ok {
+			return s.conn.reply(msg.ID, nil)
+		}
+
+		out, err := patukek_fmt.Format(filename(d.uri), d.text)
+		if err != nil || string(out) == d.text {
+			return s.conn.reply(msg.ID, []TextEdit{})
+		}
+		return s.conn.reply(msg.ID, []TextEdit{{
+			Range:   span(d.text, 0, len(d.text)),
+			NewText: string(out),
+		}})
+
+	default:
+		if msg.ID != nil {
+			return s.conn.replyError(msg.ID, codeMethodNotFound, "method not found: "+msg.Method)
+		}
+		return nil
+	}
+}
+
+// update analyses the new text of a document and publishes its
+// diagnostics. Names from the last version that parsed stay available
+// for completion while the document does not.
+func (s *Server) update(uri, text string) error {
+	d := analyze(uri, text)
+	if old, ok := s.docs[uri]; ok && d.refs == nil {
+		if d.prev = old; old.refs == nil {
+			d.prev = old.prev
+		}
+	}
+	s.docs[uri] = d
+
+	return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: d.diagnostics(),
+	})
+}
+
+func (s *Server) withPosition(msg *message, fn func(d *document, off int) any) error {
+	var p positionParams
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return s.conn.replyError(msg.ID, codeInvalidParams, err.Error())
+	}
+
+	d, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return s.conn.reply(msg.ID, nil)
+	}
+	return s.conn.reply(msg.ID, fn(d, offset(d.text, p.Position)))
+}
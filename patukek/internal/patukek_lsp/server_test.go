@@ -0,0 +1,253 @@
+package patukek_lsp
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testURI = "file:///test.ptk"
+
+// client drives a Server over in-memory pipes.
+type client struct {
+	t    *testing.T
+	conn *conn
+	id   int
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	var (
+		sr, cw = io.Pipe()
+		cr, sw = io.Pipe()
+		done   = make(chan error, 1)
+	)
+
+	go func() {
+		done <- NewServer(sr, sw).Serve()
+		sw.Close()
+	}()
+
+	t.Cleanup(func() {
+		cw.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return &client{t: t, conn: newConn(cr, cw)}
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends a request and decodes the result of its response into ret.
+func (c *client) call(method string, params, ret any) {
+	c.t.Helper()
+
+	c.id++
+	b, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	id := json.RawMessage(jsonInt(c.id))
+	if err := c.conn.write(&message{ID: &id, Method: method, Params: b}); err != nil {
+		c.t.Fatal(err)
+	}
+
+	msg := c.read()
+	if msg.Error != nil {
+		c.t.Fatalf("%s: %v", method, msg.Error)
+	}
+	decode(c.t, msg.Result, ret)
+}
+
+func (c *client) read() *message {
+	c.t.Helper()
+	msg, err := c.conn.read()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// open opens a document and returns the diagnostics published for it.
+func (c *client) open(text string) []Diagnostic {
+	c.t.Helper()
+
+	c.notify("textDocument/didOpen", didOpenParams{
+		TextDocument: textDocumentItem{URI: testURI, Version: 1, Text: text},
+	})
+
+	msg := c.read()
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("got %s, want diagnostics", msg.Method)
+	}
+
+	var p publishDiagnosticsParams
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		c.t.Fatal(err)
+	}
+	return p.Diagnostics
+}
+
+func (c *client) at(method string, line, char int, ret any) {
+	c.t.Helper()
+	c.call(method, positionParams{
+		TextDocument: textDocumentIdentifier{URI: testURI},
+		Position:     Position{Line: line, Character: char},
+	}, ret)
+}
+
+func jsonInt(n int) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func decode(t *testing.T, v, ret any) {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, ret); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	c := newClient(t)
+
+	var res struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	c.call("initialize", map[string]any{}, &res)
+
+	for _, k := range []string{"hoverProvider", "definitionProvider", "completionProvider", "documentFormattingProvider"} {
+		if _, ok := res.Capabilities[k]; !ok {
+			t.Errorf("capabilities lack %s: %v", k, res.Capabilities)
+		}
+	}
+
+	c.call("shutdown", nil, new(any))
+	c.notify("exit", nil)
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := newClient(t)
+
+	diags := c.open("a = 5\nprintln(a + c)\n")
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, want 1: %v", len(diags), diags)
+	}
+
+	want := Range{Start: Position{1, 12}, End: Position{1, 13}}
+	if d := diags[0]; d.Range != want || !strings.Contains(d.Message, "undefined variable c") {
+		t.Errorf("got %+v, want undefined variable c at %v", d, want)
+	}
+
+	if diags := c.open("a = 5\nprintln(a)\n"); len(diags) != 0 {
+		t.Errorf("got %v, want no diagnostics", diags)
+	}
+}
+
+func TestHover(t *testing.T) {
+	c := newClient(t)
+	c.open(strings.Join([]string{
+		`n = len([1, 2])`,
+		`area = patukek(w: float, h: float) -> float { w * h }`,
+		`println(n, area(1.0, 2.0), 3)`,
+	}, "\n"))
+
+	tests := []struct {
+		line, char int
+		want       string
+	}{
+		{2, 8, "n int"},
+		{2, 11, "area patukek(w: float, h: float) -> float"},
+		{1, 46, "w float (parameter)"},
+		{2, 2, "println(args...)"},
+		{2, 27, "int"},
+	}
+
+	for _, tt := range tests {
+		var h Hover
+		c.at("textDocument/hover", tt.line, tt.char, &h)
+		if want := codeBlock(tt.want); h.Contents.Value != want {
+			t.Errorf("hover at %d:%d = %q, want %q", tt.line, tt.char, h.Contents.Value, want)
+		}
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	c := newClient(t)
+	c.open("x = 1\nf = patukek(y) { x + y }\n")
+
+	var loc Location
+	c.at("textDocument/definition", 1, 17, &loc)
+	if want := (Range{Start: Position{0, 0}, End: Position{0, 1}}); loc.URI != testURI || loc.Range != want {
+		t.Errorf("definition of x = %+v, want %v", loc, want)
+	}
+
+	c.at("textDocument/definition", 1, 21, &loc)
+	if want := (Range{Start: Position{1, 12}, End: Position{1, 13}}); loc.Range != want {
+		t.Errorf("definition of y = %+v, want %v", loc, want)
+	}
+
+	var none *Location
+	c.at("textDocument/definition", 1, 7, &none)
+	if none != nil {
+		t.Errorf("definition of a keyword = %+v, want null", none)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	c := newClient(t)
+	c.open(strings.Join([]string{
+		`add = patukek(a, b) {`,
+		`	sum = a + b`,
+		`	sum`,
+		`}`,
+		`total = add(1, 2)`,
+		``,
+	}, "\n"))
+
+	labels := func(line, char int) []string {
+		var items []CompletionItem
+		c.at("textDocument/completion", line, char, &items)
+
+		var ret []string
+		for _, i := range items {
+			ret = append(ret, i.Label)
+		}
+		return ret
+	}
+
+	top := labels(5, 0)
+	for _, want := range []string{"add", "total", "len", "patukek", "return"} {
+		if !slices.Contains(top, want) {
+			t.Errorf("top-level completions lack %s: %v", want, top)
+		}
+	}
+	for _, unwanted := range []string{"a", "b", "sum", "true", "false", "null"} {
+		if slices.Contains(top, unwanted) {
+			t.Errorf("top-level completions offer %s: %v", unwanted, top)
+		}
+	}
+
+	inner := labels(2, 1)
+	for _, want := range []string{"a", "b", "sum", "add"} {
+		if !slices.Contains(inner, want) {
+			t.Errorf("completions in add lack %s: %v", want, inner)
+		}
+	}
+	if slices.Contains(inner, "total") {
+		t.Errorf("completions in add offer total, defined after it: %v", inner)
+	}
+}
@@ -0,0 +1,95 @@
+package patukek_obj
+
+import "strings"
+
+var assertBuiltins = []BuiltinImpl{
+	{
+		Name:      "assert",
+		Signature: "assert(cond[, msg])",
+		Type:      "patukek(any, [string]) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 && l != 2 {
+				return NewError("assert: wrong number of arguments, expected 1 or 2, got %d", l)
+			}
+
+			if !IsTruthy(Unwrap(args[0])) {
+				return NewFailure("assertion failed%s", assertMsg(args[1:]))
+			}
+			return NullObj
+		},
+	},
+	{
+		Name:      "assert_eq",
+		Signature: "assert_eq(got, want[, msg])",
+		Type:      "patukek(a, a, [string]) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 && l != 3 {
+				return NewError("assert_eq: wrong number of arguments, expected 2 or 3, got %d", l)
+			}
+
+			got, want := Unwrap(args[0]), Unwrap(args[1])
+			if !Equal(got, want) {
+				return NewFailure("assert_eq failed%s\n    got:  %s\n    want: %s", assertMsg(args[2:]), repr(got), repr(want))
+			}
+			return NullObj
+		},
+	},
+	{
+		Name:      "assert_error",
+		Signature: "assert_error(fn | value[, substr])",
+		Type:      "patukek(any, [string]) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 && l != 2 {
+				return NewError("assert_error: wrong number of arguments, expected 1 or 2, got %d", l)
+			}
+
+			var (
+				v   = Unwrap(args[0])
+				msg string
+			)
+
+			if AssertTypes(v, ClosureType, BuiltinType) {
+				o, err := ctx.Call(v)
+				if err != nil {
+					msg = err.Error()
+				} else {
+					v = Unwrap(o)
+				}
+			}
+
+			if msg == "" {
+				e, ok := v.(Error)
+				if !ok {
+					return NewFailure("assert_error failed\n    got:  %s\n    want: an error", repr(v))
+				}
+				msg = e.Val()
+			}
+
+			if len(args) == 2 {
+				substr, ok := Unwrap(args[1]).(String)
+				if !ok {
+					return NewError("assert_error: second argument must be a string")
+				}
+				if !strings.Contains(msg, string(substr)) {
+					return NewFailure("assert_error failed\n    got:  %s\n    want: an error containing %s", String(msg).Quoted(), substr.Quoted())
+				}
+			}
+			return NullObj
+		},
+	},
+}
+
+func assertMsg(args []Object) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return ": " + Unwrap(args[0]).String()
+}
+
+// repr returns o as it would be written in source code where possible.
+func repr(o Object) string {
+	if s, ok := o.(String); ok {
+		return s.Quoted()
+	}
+	return o.String()
+}
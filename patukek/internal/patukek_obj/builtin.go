@@ -1,17 +1,15 @@
 package patukek_obj
 
 import (
+	"bufio"
 	"fmt"
 	"io"
-	"os"
+	"slices"
 	"strconv"
+	"strings"
 )
 
-var (
-	Stdout io.Writer = os.Stdout
-)
-
-type Builtin func(args ...Object) Object
+type Builtin func(ctx Context, args ...Object) Object
 
 func (b Builtin) Type() Type {
 	return BuiltinType
@@ -22,14 +20,31 @@ func (b Builtin) String() string {
 }
 
 type BuiltinImpl struct {
-	Builtin Builtin
-	Name    string
+	Builtin   Builtin
+	Value     Object
+	Name      string
+	Signature string
+	// Type is the type scheme of the builtin in annotation syntax, such
+	// as "patukek(list[a], patukek(a) -> b) -> list[b]". Lowercase names
+	// that are not types, like a and b, are type variables.
+	Type string
+}
+
+func (b BuiltinImpl) Object() Object {
+	if b.Value != nil {
+		return b.Value
+	}
+	return b.Builtin
 }
 
-var Builtins = []BuiltinImpl{
+var Builtins = slices.Concat(coreBuiltins, stringBuiltins, mathBuiltins, listBuiltins, assertBuiltins)
+
+var coreBuiltins = []BuiltinImpl{
 	{
-		Name: "len",
-		Builtin: func(args ...Object) Object {
+		Name:      "len",
+		Signature: "len(x) int",
+		Type:      "patukek(any) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
 			if l := len(args); l != 1 {
 				return NewError("len: wrong number of arguments, expected 1, got %d", l)
 			}
@@ -39,41 +54,152 @@ var Builtins = []BuiltinImpl{
 				return Integer(len(o))
 			case String:
 				return Integer(len(o))
+			case *Vector:
+				return Integer(o.Len())
+			case *Cons:
+				return Integer(o.Len())
 			default:
 				return NewError("len: object of type %q has no length", o.Type())
 			}
 		},
 	},
 	{
-		Name: "println",
-		Builtin: func(args ...Object) Object {
-			_, _ = fmt.Fprintln(Stdout, toAnySlice(args)...)
+		Name:      "println",
+		Signature: "println(args...)",
+		Type:      "patukek(any...) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			_, _ = fmt.Fprintln(ctx.Stdout(), toAnySlice(args)...)
 			return NullObj
 		},
 	},
 	{
-		Name: "input",
-		Builtin: func(args ...Object) Object {
-			var tmp string
-
+		Name:      "eprintln",
+		Signature: "eprintln(args...)",
+		Type:      "patukek(any...) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			_, _ = fmt.Fprintln(ctx.Stderr(), toAnySlice(args)...)
+			return NullObj
+		},
+	},
+	{
+		Name:      "input",
+		Signature: "input([prompt]) string",
+		Type:      "patukek([string]) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
 			args = UnwrapAll(args)
 			switch l := len(args); l {
 			case 0:
-				_, _ = fmt.Scanln(&tmp)
 
 			case 1:
-				fmt.Print(args[0])
-				_, _ = fmt.Scanln(&tmp)
+				_, _ = fmt.Fprint(ctx.Stdout(), args[0])
 
 			default:
 				return NewError("input: wrong number of arguments, expected 1, got %d", l)
 			}
-			return NewString(tmp)
+
+			line, _ := readLine(ctx.Stdin())
+			if f := strings.Fields(line); len(f) > 0 {
+				return NewString(f[0])
+			}
+			return NewString("")
+		},
+	},
+	{
+		Name:      "readline",
+		Signature: "readline([prompt]) string | null",
+		Type:      "patukek([string]) -> any",
+		Builtin: func(ctx Context, args ...Object) Object {
+			args = UnwrapAll(args)
+			switch l := len(args); l {
+			case 0:
+
+			case 1:
+				_, _ = fmt.Fprint(ctx.Stdout(), args[0])
+
+			default:
+				return NewError("readline: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			if line, ok := readLine(ctx.Stdin()); ok {
+				return NewString(line)
+			}
+			return NullObj
+		},
+	},
+	{
+		Name:      "readall",
+		Signature: "readall() string",
+		Type:      "patukek() -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 0 {
+				return NewError("readall: wrong number of arguments, expected 0, got %d", l)
+			}
+
+			b, err := io.ReadAll(ctx.Stdin())
+			if err != nil {
+				return NewError("readall: %v", err)
+			}
+			return NewString(string(b))
 		},
 	},
 	{
-		Name: "string",
-		Builtin: func(args ...Object) Object {
+		Name:      "eof",
+		Signature: "eof() bool",
+		Type:      "patukek() -> bool",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 0 {
+				return NewError("eof: wrong number of arguments, expected 0, got %d", l)
+			}
+
+			_, err := ctx.Stdin().Peek(1)
+			return ParseBool(err != nil)
+		},
+	},
+	// lines reads all of stdin before it returns, so it suits files but not
+	// interactive input; each_line and readline read one line at a time.
+	{
+		Name:      "lines",
+		Signature: "lines() list",
+		Type:      "patukek() -> list[string]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 0 {
+				return NewError("lines: wrong number of arguments, expected 0, got %d", l)
+			}
+
+			var ret = List{}
+			for line, ok := readLine(ctx.Stdin()); ok; line, ok = readLine(ctx.Stdin()) {
+				ret = append(ret, NewString(line))
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "each_line",
+		Signature: "each_line(fn)",
+		Type:      "patukek(patukek(string) -> any) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("each_line: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			fn := Unwrap(args[0])
+			if !AssertTypes(fn, ClosureType, BuiltinType) {
+				return NewError("each_line: argument must be a function")
+			}
+
+			for line, ok := readLine(ctx.Stdin()); ok; line, ok = readLine(ctx.Stdin()) {
+				if _, err := ctx.Call(fn, NewString(line)); err != nil {
+					return NewRuntimeError(err)
+				}
+			}
+			return NullObj
+		},
+	},
+	{
+		Name:      "string",
+		Signature: "string(args...) string",
+		Type:      "patukek(any...) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
 			if len(args) == 0 {
 				return NewError("string: no argument provided")
 			}
@@ -83,14 +209,18 @@ var Builtins = []BuiltinImpl{
 		},
 	},
 	{
-		Name: "error",
-		Builtin: func(args ...Object) Object {
+		Name:      "error",
+		Signature: "error(args...) error",
+		Type:      "patukek(any...) -> error",
+		Builtin: func(ctx Context, args ...Object) Object {
 			return NewError(fmt.Sprint(toAnySlice(args)...))
 		},
 	},
 	{
-		Name: "int",
-		Builtin: func(args ...Object) Object {
+		Name:      "int",
+		Signature: "int(x) int",
+		Type:      "patukek(any) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
 			if l := len(args); l != 1 {
 				return NewError("int: wrong number of arguments, expected 1, got %d", l)
 			}
@@ -100,6 +230,9 @@ var Builtins = []BuiltinImpl{
 			case Integer:
 				return o
 
+			case Float:
+				return Integer(o)
+
 			case String:
 				if a, err := strconv.ParseInt(string(o), 10, 64); err == nil {
 					return Integer(a)
@@ -112,35 +245,78 @@ var Builtins = []BuiltinImpl{
 		},
 	},
 	{
-		Name: "append",
-		Builtin: func(args ...Object) Object {
+		Name:      "float",
+		Signature: "float(x) float",
+		Type:      "patukek(any) -> float",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("float: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			switch o := args[0].(type) {
+			case Integer:
+				return Float(o)
+
+			case Float:
+				return o
+
+			case String:
+				if a, err := strconv.ParseFloat(string(o), 64); err == nil {
+					return Float(a)
+				}
+				return NewError("%v is not a number", args[0])
+
+			default:
+				return NewError("%v is not a number", args[0])
+			}
+		},
+	},
+	{
+		Name:      "append",
+		Signature: "append(l, elems...) list",
+		Type:      "patukek(list[a], a...) -> list[a]",
+		Builtin: func(ctx Context, args ...Object) Object {
 			if len(args) == 0 {
 				return NewError("append: no argument provided")
 			}
 
 			args = UnwrapAll(args)
-			lst, ok := args[0].(List)
-			if !ok {
-				return NewError("append: first argument must be a list")
-			}
+			switch lst := args[0].(type) {
+			case List:
+				return slices.Concat(lst, args[1:])
 
-			if len(args) > 1 {
-				return append(lst, args[1:]...)
+			case *Vector:
+				for _, a := range args[1:] {
+					lst = lst.Append(a)
+				}
+				return lst
+
+			default:
+				return NewError("append: first argument must be a list or a vector")
 			}
-			return lst
 		},
 	},
 	{
-		Name: "push",
-		Builtin: func(args ...Object) Object {
+		Name:      "push",
+		Signature: "push(l, elems...) list",
+		Type:      "patukek(list[a], a...) -> list[a]",
+		Builtin: func(ctx Context, args ...Object) Object {
 			if len(args) == 0 {
 				return NewError("push: no argument provided")
 			}
 
 			args = UnwrapAll(args)
+			if c, ok := args[0].(*Cons); ok {
+				for _, a := range args[1:] {
+					c = c.Prepend(a)
+				}
+				return c
+			}
+
 			lst, ok := args[0].(List)
 			if !ok {
-				return NewError("push: first argument must be a list")
+				return NewError("push: first argument must be a list or a cons list")
 			}
 
 			if len(args) > 1 {
@@ -157,6 +333,16 @@ var Builtins = []BuiltinImpl{
 	},
 }
 
+func readLine(r *bufio.Reader) (string, bool) {
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), true
+}
+
 func UnwrapAll(a []Object) []Object {
 	for i, o := range a {
 		a[i] = Unwrap(o)
@@ -0,0 +1,59 @@
+package patukek_obj
+
+import "fmt"
+
+// Cons is an immutable singly linked list. The empty list is EmptyCons;
+// Prepend shares the receiver as the tail of the new list.
+type Cons struct {
+	head Object
+	tail *Cons
+	len  int
+}
+
+var EmptyCons = &Cons{}
+
+func NewCons(elems ...Object) *Cons {
+	c := EmptyCons
+	for i := len(elems) - 1; i >= 0; i-- {
+		c = c.Prepend(elems[i])
+	}
+	return c
+}
+
+func (c *Cons) Type() Type {
+	return ConsType
+}
+
+func (c *Cons) String() string {
+	return fmt.Sprintf("clist(%s)", joinElems(c.Elems()))
+}
+
+func (c *Cons) Len() int {
+	return c.len
+}
+
+func (c *Cons) Head() (Object, bool) {
+	if c.len == 0 {
+		return nil, false
+	}
+	return c.head, true
+}
+
+func (c *Cons) Tail() (*Cons, bool) {
+	if c.len == 0 {
+		return nil, false
+	}
+	return c.tail, true
+}
+
+func (c *Cons) Prepend(o Object) *Cons {
+	return &Cons{head: o, tail: c, len: c.len + 1}
+}
+
+func (c *Cons) Elems() []Object {
+	ret := make([]Object, 0, c.len)
+	for n := c; n.len > 0; n = n.tail {
+		ret = append(ret, n.head)
+	}
+	return ret
+}
@@ -0,0 +1,57 @@
+package patukek_obj
+
+import "testing"
+
+func TestCons(t *testing.T) {
+	c := NewCons(Integer(1), Integer(2), Integer(3))
+
+	if got, want := c.String(), "clist(1, 2, 3)"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if c.Len() != 4-i {
+			t.Fatalf("Len() = %d, want %d", c.Len(), 4-i)
+		}
+		if h, ok := c.Head(); !ok || !Equal(h, Integer(i)) {
+			t.Fatalf("Head() = %v, %v, want %d", h, ok, i)
+		}
+
+		var ok bool
+		if c, ok = c.Tail(); !ok {
+			t.Fatalf("Tail() of a list of %d elements failed", 4-i)
+		}
+	}
+
+	if c != EmptyCons {
+		t.Errorf("the tail of the last element is %v, want EmptyCons", c)
+	}
+	if h, ok := c.Head(); ok {
+		t.Errorf("Head() of the empty list = %v", h)
+	}
+	if tl, ok := c.Tail(); ok {
+		t.Errorf("Tail() of the empty list = %v", tl)
+	}
+}
+
+func TestConsPrepend(t *testing.T) {
+	tail := NewCons(Integer(2), Integer(3))
+	a, b := tail.Prepend(String("a")), tail.Prepend(String("b"))
+
+	for _, tt := range []struct {
+		c    *Cons
+		want []Object
+	}{
+		{tail, []Object{Integer(2), Integer(3)}},
+		{a, []Object{String("a"), Integer(2), Integer(3)}},
+		{b, []Object{String("b"), Integer(2), Integer(3)}},
+	} {
+		if got := List(tt.c.Elems()); !Equal(got, List(tt.want)) {
+			t.Errorf("Elems() = %v, want %v", got, tt.want)
+		}
+	}
+
+	if at, _ := a.Tail(); at != tail {
+		t.Error("Prepend copied its receiver instead of sharing it")
+	}
+}
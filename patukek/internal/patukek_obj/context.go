@@ -0,0 +1,17 @@
+package patukek_obj
+
+import (
+	"bufio"
+	"io"
+	"math/rand/v2"
+)
+
+type Context interface {
+	Stdin() *bufio.Reader
+	Stdout() io.Writer
+	Stderr() io.Writer
+	Call(fn Object, args ...Object) (Object, error)
+	Rand() *rand.Rand
+	Seed(seed uint64)
+	CheckSize(n int) error
+}
@@ -0,0 +1,162 @@
+package patukek_obj
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strings"
+	"unsafe"
+)
+
+// Equal reports whether a and b hold the same value. Lists, vectors and
+// cons lists are compared element by element; a pair of sequences that is
+// already being compared further up is assumed equal, so cyclic values
+// terminate.
+func Equal(a, b Object) bool {
+	var c comparer
+	return c.equal(a, b)
+}
+
+// Compare orders a and b, returning -1, 0 or 1. Numbers, strings and
+// booleans compare by value, sequences of the same kind lexicographically.
+func Compare(a, b Object) (int, error) {
+	var c comparer
+	return c.compare(a, b)
+}
+
+type comparer struct {
+	seen map[[2]unsafe.Pointer]bool
+}
+
+// visit records the pair (p, q) and reports whether it was already seen.
+func (c *comparer) visit(p, q unsafe.Pointer) bool {
+	if c.seen == nil {
+		c.seen = make(map[[2]unsafe.Pointer]bool)
+	}
+
+	k := [2]unsafe.Pointer{p, q}
+	if c.seen[k] {
+		return true
+	}
+	c.seen[k] = true
+	return false
+}
+
+func (c *comparer) equal(a, b Object) bool {
+	a, b = Unwrap(a), Unwrap(b)
+
+	if pa, ea, ok := sequence(a); ok {
+		pb, eb, ok := sequence(b)
+		if !ok || a.Type() != b.Type() || len(ea) != len(eb) {
+			return false
+		}
+		if pa == pb || c.visit(pa, pb) {
+			return true
+		}
+
+		for i := range ea {
+			if !c.equal(ea[i], eb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch a := a.(type) {
+	case Integer:
+		switch b := b.(type) {
+		case Integer:
+			return a == b
+		case Float:
+			return equalIntFloat(a, b)
+		default:
+			return false
+		}
+
+	case Float:
+		switch b := b.(type) {
+		case Integer:
+			return equalIntFloat(b, a)
+		case Float:
+			return a == b
+		default:
+			return false
+		}
+
+	case String, *Boolean, *Null:
+		return a == b
+
+	default:
+		return false
+	}
+}
+
+func (c *comparer) compare(a, b Object) (int, error) {
+	a, b = Unwrap(a), Unwrap(b)
+
+	if pa, ea, ok := sequence(a); ok {
+		pb, eb, ok := sequence(b)
+		if !ok || a.Type() != b.Type() {
+			return 0, fmt.Errorf("cannot compare %v and %v", a.Type(), b.Type())
+		}
+		if pa == pb || c.visit(pa, pb) {
+			return 0, nil
+		}
+
+		for i := 0; i < len(ea) && i < len(eb); i++ {
+			if r, err := c.compare(ea[i], eb[i]); err != nil || r != 0 {
+				return r, err
+			}
+		}
+		return cmp.Compare(len(ea), len(eb)), nil
+	}
+
+	switch {
+	case AssertTypes(a, IntType) && AssertTypes(b, IntType):
+		return cmp.Compare(a.(Integer), b.(Integer)), nil
+
+	case AssertTypes(a, IntType, FloatType) && AssertTypes(b, IntType, FloatType):
+		l, _ := ToFloat(a)
+		r, _ := ToFloat(b)
+		return cmp.Compare(l, r), nil
+
+	case AssertTypes(a, StringType) && AssertTypes(b, StringType):
+		return strings.Compare(string(a.(String)), string(b.(String))), nil
+
+	case AssertTypes(a, BoolType) && AssertTypes(b, BoolType):
+		return cmp.Compare(boolRank(a), boolRank(b)), nil
+
+	default:
+		return 0, fmt.Errorf("cannot compare %v and %v", a.Type(), b.Type())
+	}
+}
+
+// equalIntFloat reports whether f holds exactly the value of i. Converting
+// i to a float instead would round large integers, making them equal to
+// floats that hash differently.
+func equalIntFloat(i Integer, f Float) bool {
+	t := math.Trunc(float64(f))
+	return t == float64(f) && t >= math.MinInt64 && t < math.MaxInt64 && Integer(t) == i
+}
+
+// sequence returns the identity and the elements of a list, vector or
+// cons list.
+func sequence(o Object) (unsafe.Pointer, []Object, bool) {
+	switch o := o.(type) {
+	case List:
+		return unsafe.Pointer(unsafe.SliceData(o)), o, true
+	case *Vector:
+		return unsafe.Pointer(o), o.Elems(), true
+	case *Cons:
+		return unsafe.Pointer(o), o.Elems(), true
+	default:
+		return nil, nil, false
+	}
+}
+
+func boolRank(o Object) int {
+	if o == True {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package patukek_obj
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	cyclic := NewList(Integer(1), nil).(List)
+	cyclic[1] = cyclic
+
+	tests := []struct {
+		name string
+		a, b Object
+		want bool
+	}{
+		{"integers", Integer(1), Integer(1), true},
+		{"integer and float", Integer(1), Float(1.0), true},
+		{"float and integer", Float(2.0), Integer(2), true},
+		{"fraction", Integer(1), Float(1.5), false},
+		{"rounded integer", Integer(1<<53 + 1), Float(1 << 53), false},
+		{"float beyond integers", Integer(math.MaxInt64), Float(math.MaxInt64), false},
+		{"strings", String("a"), String("a"), true},
+		{"integer and string", Integer(1), String("1"), false},
+		{"booleans", True, True, true},
+		{"null", NullObj, NullObj, true},
+		{"lists", NewList(Integer(1), Float(2)), NewList(Float(1), Integer(2)), true},
+		{"list lengths", NewList(Integer(1)), NewList(Integer(1), Integer(2)), false},
+		{"list and vector", NewList(Integer(1)), NewVector(Integer(1)), false},
+		{"vectors", NewVector(Integer(1)), NewVector(Integer(1)), true},
+		{"cons lists", NewCons(String("a")), NewCons(String("a")), true},
+		{"cyclic", cyclic, cyclic, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKeyHash checks that values which are Equal hash alike.
+func TestKeyHash(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Object
+	}{
+		{"integer and float", Integer(1), Float(1.0)},
+		{"negative zero", Integer(0), Float(math.Copysign(0, -1))},
+		{"smallest integer", Integer(math.MinInt64), Float(math.MinInt64)},
+		{"lists", NewList(Integer(1)), NewList(Float(1.0))},
+		{"nested lists", NewList(NewList(Integer(1), String("a"))), NewList(NewList(Float(1), String("a")))},
+		{"vectors", NewVector(Integer(1), Float(2)), NewVector(Float(1), Integer(2))},
+		{"cons lists", NewCons(True, NullObj), NewCons(True, NullObj)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !Equal(tt.a, tt.b) {
+				t.Fatalf("%v and %v are not equal", tt.a, tt.b)
+			}
+
+			ha, hb := tt.a.(Hashable).KeyHash(), tt.b.(Hashable).KeyHash()
+			if ha != hb {
+				t.Errorf("KeyHash(%v) = %v, KeyHash(%v) = %v", tt.a, ha, tt.b, hb)
+			}
+		})
+	}
+}
+
+func TestKeyHashDistinct(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Object
+	}{
+		{"integers", Integer(1), Integer(2)},
+		{"list order", NewList(Integer(1), Integer(2)), NewList(Integer(2), Integer(1))},
+		{"list and vector", NewList(Integer(1)), NewVector(Integer(1))},
+		{"integer and string", NewList(Integer(1)), NewList(String("1"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a.(Hashable).KeyHash() == tt.b.(Hashable).KeyHash() {
+				t.Errorf("%v and %v hash alike", tt.a, tt.b)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package patukek_obj
+
+import "fmt"
+
+// Failure is returned by builtins to abort the program. Unlike Error
+// values, which are ordinary results, a failure is reported by the VM
+// as a runtime error located at the call site.
+type Failure struct {
+	Msg string
+}
+
+func NewFailure(f string, a ...any) Object {
+	return &Failure{Msg: fmt.Sprintf(f, a...)}
+}
+
+func (f *Failure) Type() Type {
+	return ErrorType
+}
+
+func (f *Failure) String() string {
+	return f.Msg
+}
@@ -0,0 +1,48 @@
+package patukek_obj
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Float float64
+
+func NewFloat(f float64) Object {
+	return Float(f)
+}
+
+func (f Float) String() string {
+	s := strconv.FormatFloat(float64(f), 'g', -1, 64)
+	if math.IsInf(float64(f), 0) || math.IsNaN(float64(f)) || strings.ContainsAny(s, ".e") {
+		return s
+	}
+	return s + ".0"
+}
+
+func (f Float) Type() Type {
+	return FloatType
+}
+
+func (f Float) Val() float64 {
+	return float64(f)
+}
+
+func (f Float) KeyHash() KeyHash {
+	// Whole floats hash like the equal integer, since 1 == 1.0.
+	if t := math.Trunc(float64(f)); t == float64(f) && t >= math.MinInt64 && t < math.MaxInt64 {
+		return Integer(t).KeyHash()
+	}
+	return KeyHash{Type: FloatType, Value: math.Float64bits(float64(f))}
+}
+
+func ToFloat(o Object) (float64, bool) {
+	switch o := o.(type) {
+	case Integer:
+		return float64(o), true
+	case Float:
+		return float64(o), true
+	default:
+		return 0, false
+	}
+}
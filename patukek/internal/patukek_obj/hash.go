@@ -0,0 +1,43 @@
+package patukek_obj
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+func (l List) KeyHash() KeyHash {
+	return hashElems(ListType, l)
+}
+
+func (v *Vector) KeyHash() KeyHash {
+	return hashElems(VectorType, v.Elems())
+}
+
+func (c *Cons) KeyHash() KeyHash {
+	return hashElems(ConsType, c.Elems())
+}
+
+// hashElems combines the hashes of elems, so sequences that are Equal hash
+// alike. Elements that are not hashable only contribute their type.
+func hashElems(t Type, elems []Object) KeyHash {
+	var (
+		h   = fnv.New64a()
+		buf [16]byte
+	)
+
+	for _, e := range elems {
+		e = Unwrap(e)
+
+		var k KeyHash
+		if eh, ok := e.(Hashable); ok {
+			k = eh.KeyHash()
+		} else {
+			k.Type = e.Type()
+		}
+
+		binary.LittleEndian.PutUint64(buf[:8], uint64(k.Type))
+		binary.LittleEndian.PutUint64(buf[8:], k.Value)
+		_, _ = h.Write(buf[:])
+	}
+	return KeyHash{Type: t, Value: h.Sum64()}
+}
@@ -16,18 +16,22 @@ func (l List) Type() Type {
 }
 
 func (l List) String() string {
+	return fmt.Sprintf("[%s]", joinElems(l))
+}
+
+func (l List) Val() []Object {
+	return l
+}
+
+func joinElems(elems []Object) string {
 	var elements []string
 
-	for _, e := range l {
+	for _, e := range elems {
 		if s, ok := e.(String); ok {
 			elements = append(elements, s.Quoted())
 		} else {
 			elements = append(elements, e.String())
 		}
 	}
-	return fmt.Sprintf("[%s]", strings.Join(elements, ", "))
+	return strings.Join(elements, ", ")
 }
-
-func (l List) Val() []Object {
-	return l
-}
\ No newline at end of file
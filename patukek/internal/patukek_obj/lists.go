@@ -0,0 +1,576 @@
+package patukek_obj
+
+import "slices"
+
+var listBuiltins = []BuiltinImpl{
+	{
+		Name:      "map",
+		Signature: "map(l, fn) list",
+		Type:      "patukek(list[a], patukek(a) -> b) -> list[b]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			lst, fn, err := listAndFunc("map", args)
+			if err != nil {
+				return err
+			}
+
+			ret := make(List, len(lst))
+			for i, e := range lst {
+				o, err := ctx.Call(fn, e)
+				if err != nil {
+					return NewRuntimeError(err)
+				}
+				ret[i] = o
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "filter",
+		Signature: "filter(l, fn) list",
+		Type:      "patukek(list[a], patukek(a) -> any) -> list[a]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			lst, fn, err := listAndFunc("filter", args)
+			if err != nil {
+				return err
+			}
+
+			ret := List{}
+			for _, e := range lst {
+				o, err := ctx.Call(fn, e)
+				if err != nil {
+					return NewRuntimeError(err)
+				}
+				if IsTruthy(Unwrap(o)) {
+					ret = append(ret, e)
+				}
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "reduce",
+		Signature: "reduce(l, fn[, init])",
+		Type:      "patukek(list[a], patukek(b, a) -> b, [b]) -> b",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 && l != 3 {
+				return NewError("reduce: wrong number of arguments, expected 2 or 3, got %d", l)
+			}
+
+			lst, fn, err := listAndFunc("reduce", args[:2])
+			if err != nil {
+				return err
+			}
+
+			var acc Object
+			switch {
+			case len(args) == 3:
+				acc = Unwrap(args[2])
+			case len(lst) > 0:
+				acc, lst = lst[0], lst[1:]
+			default:
+				return NewError("reduce: empty list and no initial value")
+			}
+
+			for _, e := range lst {
+				o, err := ctx.Call(fn, acc, e)
+				if err != nil {
+					return NewRuntimeError(err)
+				}
+				acc = o
+			}
+			return acc
+		},
+	},
+	{
+		Name:      "fold_right",
+		Signature: "fold_right(l, fn, init)",
+		Type:      "patukek(list[a], patukek(a, b) -> b, b) -> b",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 3 {
+				return NewError("fold_right: wrong number of arguments, expected 3, got %d", l)
+			}
+
+			lst, fn, err := listAndFunc("fold_right", args[:2])
+			if err != nil {
+				return err
+			}
+
+			acc := Unwrap(args[2])
+			for i := len(lst) - 1; i >= 0; i-- {
+				o, err := ctx.Call(fn, lst[i], acc)
+				if err != nil {
+					return NewRuntimeError(err)
+				}
+				acc = o
+			}
+			return acc
+		},
+	},
+	{
+		Name:      "flat_map",
+		Signature: "flat_map(l, fn) list",
+		Type:      "patukek(list[a], patukek(a) -> list[b]) -> list[b]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			lst, fn, err := listAndFunc("flat_map", args)
+			if err != nil {
+				return err
+			}
+
+			ret := List{}
+			for _, e := range lst {
+				o, err := ctx.Call(fn, e)
+				if err != nil {
+					return NewRuntimeError(err)
+				}
+
+				l, ok := Unwrap(o).(List)
+				if !ok {
+					return NewError("flat_map: function must return a list, got %v", o.Type())
+				}
+				if err := ctx.CheckSize(len(ret) + len(l)); err != nil {
+					return NewRuntimeError(err)
+				}
+				ret = append(ret, l...)
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "any",
+		Signature: "any(l[, fn]) bool",
+		Type:      "patukek(list[a], [patukek(a) -> any]) -> bool",
+		Builtin: func(ctx Context, args ...Object) Object {
+			return quantify(ctx, "any", args, true)
+		},
+	},
+	{
+		Name:      "all",
+		Signature: "all(l[, fn]) bool",
+		Type:      "patukek(list[a], [patukek(a) -> any]) -> bool",
+		Builtin: func(ctx Context, args ...Object) Object {
+			return quantify(ctx, "all", args, false)
+		},
+	},
+	{
+		Name:      "zip",
+		Signature: "zip(lists...) list",
+		Type:      "patukek(list[a]...) -> list[list[a]]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if len(args) == 0 {
+				return NewError("zip: no argument provided")
+			}
+
+			var (
+				lists = make([]List, len(args))
+				n     = -1
+			)
+
+			for i, a := range args {
+				l, ok := Unwrap(a).(List)
+				if !ok {
+					return NewError("zip: argument %d must be a list", i+1)
+				}
+				if lists[i] = l; n < 0 || len(l) < n {
+					n = len(l)
+				}
+			}
+
+			ret := make(List, n)
+			for i := range ret {
+				tuple := make(List, len(lists))
+				for j, l := range lists {
+					tuple[j] = l[i]
+				}
+				ret[i] = tuple
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "enumerate",
+		Signature: "enumerate(l) list",
+		Type:      "patukek(list[a]) -> list[list[any]]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("enumerate: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			lst, ok := Unwrap(args[0]).(List)
+			if !ok {
+				return NewError("enumerate: argument must be a list")
+			}
+
+			ret := make(List, len(lst))
+			for i, e := range lst {
+				ret[i] = List{Integer(i), e}
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "range",
+		Signature: "range([start, ]stop[, step]) list",
+		Type:      "patukek(int, [int], [int]) -> list[int]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			var start, stop, step Integer = 0, 0, 1
+
+			args = UnwrapAll(args)
+			for i, a := range args {
+				if _, ok := a.(Integer); !ok {
+					return NewError("range: argument %d must be an int", i+1)
+				}
+			}
+
+			switch l := len(args); l {
+			case 1:
+				stop = args[0].(Integer)
+			case 2:
+				start, stop = args[0].(Integer), args[1].(Integer)
+			case 3:
+				start, stop, step = args[0].(Integer), args[1].(Integer), args[2].(Integer)
+			default:
+				return NewError("range: wrong number of arguments, expected 1 to 3, got %d", l)
+			}
+
+			if step == 0 {
+				return NewError("range: step must not be 0")
+			}
+
+			var n int
+			if step > 0 && stop > start {
+				n = int((stop - start + step - 1) / step)
+			} else if step < 0 && stop < start {
+				n = int((start - stop - step - 1) / -step)
+			}
+			if err := ctx.CheckSize(n); err != nil {
+				return NewRuntimeError(err)
+			}
+
+			ret := make(List, n)
+			for i := range ret {
+				ret[i] = start + Integer(i)*step
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "sort",
+		Signature: "sort(l[, cmp]) list",
+		Type:      "patukek(list[a], [patukek(a, a) -> any]) -> list[a]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			var cmp Object
+
+			switch l := len(args); l {
+			case 1:
+			case 2:
+				cmp = Unwrap(args[1])
+				if !AssertTypes(cmp, ClosureType, BuiltinType) {
+					return NewError("sort: second argument must be a function")
+				}
+			default:
+				return NewError("sort: wrong number of arguments, expected 1 or 2, got %d", l)
+			}
+
+			lst, ok := Unwrap(args[0]).(List)
+			if !ok {
+				return NewError("sort: first argument must be a list")
+			}
+
+			var (
+				ret  = slices.Clone(lst)
+				fail Object
+			)
+
+			call := func(a, b Object) Object {
+				o, err := ctx.Call(cmp, a, b)
+				if err != nil {
+					fail = NewRuntimeError(err)
+					return nil
+				}
+
+				o = Unwrap(o)
+				if !AssertTypes(o, IntType, BoolType) {
+					fail = NewError("sort: comparator must return an int or a bool, got %v", o.Type())
+					return nil
+				}
+				return o
+			}
+
+			slices.SortStableFunc(ret, func(a, b Object) int {
+				if fail != nil {
+					return 0
+				}
+
+				if cmp == nil {
+					c, err := Compare(a, b)
+					if err != nil {
+						fail = NewError("sort: %v", err)
+					}
+					return c
+				}
+
+				switch o := call(a, b).(type) {
+				case Integer:
+					return int(o)
+				case *Boolean:
+					// A bool comparator reports a < b; elements where
+					// neither is less keep their order.
+					if *o {
+						return -1
+					}
+					if r, ok := call(b, a).(*Boolean); ok && bool(*r) {
+						return 1
+					}
+					return 0
+				default:
+					return 0
+				}
+			})
+
+			if fail != nil {
+				return fail
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "compare",
+		Signature: "compare(a, b) int",
+		Type:      "patukek(a, a) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("compare: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			c, err := Compare(args[0], args[1])
+			if err != nil {
+				return NewError("compare: %v", err)
+			}
+			return Integer(c)
+		},
+	},
+	{
+		Name:      "vector",
+		Signature: "vector(elems...) vector",
+		Type:      "patukek(any...) -> vector",
+		Builtin: func(ctx Context, args ...Object) Object {
+			return NewVector(UnwrapAll(args)...)
+		},
+	},
+	{
+		Name:      "clist",
+		Signature: "clist(elems...) cons",
+		Type:      "patukek(any...) -> cons",
+		Builtin: func(ctx Context, args ...Object) Object {
+			return NewCons(UnwrapAll(args)...)
+		},
+	},
+	{
+		Name:      "cons",
+		Signature: "cons(x[, tail]) cons",
+		Type:      "patukek(any, [cons]) -> cons",
+		Builtin: func(ctx Context, args ...Object) Object {
+			switch l := len(args); l {
+			case 1:
+				return EmptyCons.Prepend(Unwrap(args[0]))
+			case 2:
+				c, ok := Unwrap(args[1]).(*Cons)
+				if !ok {
+					return NewError("cons: second argument must be a cons list")
+				}
+				return c.Prepend(Unwrap(args[0]))
+			default:
+				return NewError("cons: wrong number of arguments, expected 1 or 2, got %d", l)
+			}
+		},
+	},
+	{
+		Name:      "head",
+		Signature: "head(c)",
+		Type:      "patukek(cons) -> any",
+		Builtin: func(ctx Context, args ...Object) Object {
+			c, err := consArg("head", args)
+			if err != nil {
+				return err
+			}
+
+			h, ok := c.Head()
+			if !ok {
+				return NewError("head: empty list")
+			}
+			return h
+		},
+	},
+	{
+		Name:      "tail",
+		Signature: "tail(c) cons",
+		Type:      "patukek(cons) -> cons",
+		Builtin: func(ctx Context, args ...Object) Object {
+			c, err := consArg("tail", args)
+			if err != nil {
+				return err
+			}
+
+			t, ok := c.Tail()
+			if !ok {
+				return NewError("tail: empty list")
+			}
+			return t
+		},
+	},
+	{
+		Name:      "get",
+		Signature: "get(l, i)",
+		Type:      "patukek(any, int) -> any",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("get: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			i, ok := args[1].(Integer)
+			if !ok {
+				return NewError("get: index must be an int")
+			}
+
+			switch o := args[0].(type) {
+			case List:
+				if i < 0 || int(i) >= len(o) {
+					return NewError("get: index %d out of range [0, %d)", i, len(o))
+				}
+				return o[i]
+
+			case *Vector:
+				e, ok := o.Get(int(i))
+				if !ok {
+					return NewError("get: index %d out of range [0, %d)", i, o.Len())
+				}
+				return e
+
+			default:
+				return NewError("get: first argument must be a list or a vector")
+			}
+		},
+	},
+	{
+		Name:      "set",
+		Signature: "set(l, i, x)",
+		Type:      "patukek(any, int, any) -> any",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 3 {
+				return NewError("set: wrong number of arguments, expected 3, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			i, ok := args[1].(Integer)
+			if !ok {
+				return NewError("set: index must be an int")
+			}
+
+			switch o := args[0].(type) {
+			case List:
+				if i < 0 || int(i) >= len(o) {
+					return NewError("set: index %d out of range [0, %d)", i, len(o))
+				}
+				ret := slices.Clone(o)
+				ret[i] = args[2]
+				return ret
+
+			case *Vector:
+				v, ok := o.Update(int(i), args[2])
+				if !ok {
+					return NewError("set: index %d out of range [0, %d)", i, o.Len())
+				}
+				return v
+
+			default:
+				return NewError("set: first argument must be a list or a vector")
+			}
+		},
+	},
+	{
+		Name:      "to_list",
+		Signature: "to_list(x) list",
+		Type:      "patukek(any) -> list[any]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("to_list: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			switch o := Unwrap(args[0]).(type) {
+			case List:
+				return o
+			case *Vector:
+				return List(o.Elems())
+			case *Cons:
+				return List(o.Elems())
+			default:
+				return NewError("to_list: cannot convert %v to a list", o.Type())
+			}
+		},
+	},
+}
+
+func consArg(name string, args []Object) (*Cons, Object) {
+	if l := len(args); l != 1 {
+		return nil, NewError("%s: wrong number of arguments, expected 1, got %d", name, l)
+	}
+
+	c, ok := Unwrap(args[0]).(*Cons)
+	if !ok {
+		return nil, NewError("%s: argument must be a cons list", name)
+	}
+	return c, nil
+}
+
+func listAndFunc(name string, args []Object) (List, Object, Object) {
+	if l := len(args); l != 2 {
+		return nil, nil, NewError("%s: wrong number of arguments, expected 2, got %d", name, l)
+	}
+
+	lst, ok := Unwrap(args[0]).(List)
+	if !ok {
+		return nil, nil, NewError("%s: first argument must be a list", name)
+	}
+
+	fn := Unwrap(args[1])
+	if !AssertTypes(fn, ClosureType, BuiltinType) {
+		return nil, nil, NewError("%s: second argument must be a function", name)
+	}
+	return lst, fn, nil
+}
+
+func quantify(ctx Context, name string, args []Object, want bool) Object {
+	var fn Object
+
+	switch l := len(args); l {
+	case 1:
+	case 2:
+		fn = Unwrap(args[1])
+		if !AssertTypes(fn, ClosureType, BuiltinType) {
+			return NewError("%s: second argument must be a function", name)
+		}
+	default:
+		return NewError("%s: wrong number of arguments, expected 1 or 2, got %d", name, l)
+	}
+
+	lst, ok := Unwrap(args[0]).(List)
+	if !ok {
+		return NewError("%s: first argument must be a list", name)
+	}
+
+	for _, e := range lst {
+		o := e
+		if fn != nil {
+			var err error
+			if o, err = ctx.Call(fn, e); err != nil {
+				return NewRuntimeError(err)
+			}
+		}
+
+		if IsTruthy(Unwrap(o)) == want {
+			return ParseBool(want)
+		}
+	}
+	return ParseBool(!want)
+}
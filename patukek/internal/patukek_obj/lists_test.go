@@ -0,0 +1,129 @@
+package patukek_obj
+
+import "testing"
+
+func TestSortComparator(t *testing.T) {
+	pair := func(k int, v string) Object {
+		return NewList(Integer(k), String(v))
+	}
+	byKey := Builtin(func(ctx Context, args ...Object) Object {
+		return NewBoolean(args[0].(List)[0].(Integer) < args[1].(List)[0].(Integer))
+	})
+	byKeyInt := Builtin(func(ctx Context, args ...Object) Object {
+		return args[0].(List)[0].(Integer) - args[1].(List)[0].(Integer)
+	})
+
+	in := NewList(pair(1, "a"), pair(0, "b"), pair(1, "c"), pair(0, "d"), pair(1, "e"))
+	want := NewList(pair(0, "b"), pair(0, "d"), pair(1, "a"), pair(1, "c"), pair(1, "e"))
+
+	for _, cmp := range []Object{byKey, byKeyInt} {
+		if got := callBuiltin(t, "sort", in, cmp); !Equal(got, want) {
+			t.Errorf("sort(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestSortErrors(t *testing.T) {
+	str := Builtin(func(ctx Context, args ...Object) Object {
+		return String("a")
+	})
+
+	tests := []struct {
+		name string
+		args []Object
+	}{
+		{"comparator result", []Object{NewList(Integer(2), Integer(1)), str}},
+		{"not a list", []Object{Integer(1)}},
+		{"not a function", []Object{NewList(), Integer(1)}},
+		{"incomparable elements", []Object{NewList(Integer(1), String("a"))}},
+	}
+
+	for _, tt := range tests {
+		if got := callBuiltin(t, "sort", tt.args...); got.Type() != ErrorType {
+			t.Errorf("%s: sort%v = %v, want an error", tt.name, tt.args, got)
+		}
+	}
+}
+
+func TestPersistentBuiltins(t *testing.T) {
+	ints := func(n int) []Object {
+		ret := make([]Object, n)
+		for i := range ret {
+			ret[i] = Integer(i)
+		}
+		return ret
+	}
+
+	for _, n := range []int{1, 32, 33, 1025} {
+		elems := ints(n)
+		v := callBuiltin(t, "vector", elems...)
+
+		if got := callBuiltin(t, "to_list", v); !Equal(got, List(elems)) {
+			t.Errorf("to_list(vector(0..%d)) = %v", n, got)
+		}
+		if got := callBuiltin(t, "get", v, Integer(n-1)); !Equal(got, Integer(n-1)) {
+			t.Errorf("get(vector(0..%d), %d) = %v", n, n-1, got)
+		}
+
+		u := callBuiltin(t, "set", v, Integer(n/2), String("x"))
+		if got := callBuiltin(t, "get", u, Integer(n/2)); !Equal(got, String("x")) {
+			t.Errorf("get(set(vector(0..%d), %d, \"x\"), %d) = %v", n, n/2, n/2, got)
+		}
+		if got := callBuiltin(t, "to_list", v); !Equal(got, List(elems)) {
+			t.Errorf("set changed vector(0..%d) to %v", n, got)
+		}
+
+		c := callBuiltin(t, "clist", elems...)
+		if got := callBuiltin(t, "to_list", c); !Equal(got, List(elems)) {
+			t.Errorf("to_list(clist(0..%d)) = %v", n, got)
+		}
+	}
+
+	c := callBuiltin(t, "cons", Integer(1), callBuiltin(t, "cons", Integer(2)))
+	if got := callBuiltin(t, "head", c); !Equal(got, Integer(1)) {
+		t.Errorf("head(cons(1, cons(2))) = %v", got)
+	}
+	tail := callBuiltin(t, "tail", c)
+	if got := callBuiltin(t, "to_list", tail); !Equal(got, NewList(Integer(2))) {
+		t.Errorf("tail(cons(1, cons(2))) = %v", got)
+	}
+	if got := callBuiltin(t, "to_list", callBuiltin(t, "tail", tail)); !Equal(got, NewList()) {
+		t.Errorf("tail(tail(cons(1, cons(2)))) = %v", got)
+	}
+
+	l := NewList(Integer(1), Integer(2))
+	if got := callBuiltin(t, "set", l, Integer(0), Integer(5)); !Equal(got, NewList(Integer(5), Integer(2))) {
+		t.Errorf("set(%v, 0, 5) = %v", l, got)
+	}
+	if !Equal(l, NewList(Integer(1), Integer(2))) {
+		t.Errorf("set changed its list to %v", l)
+	}
+}
+
+func TestPersistentBuiltinErrors(t *testing.T) {
+	v := NewVector(Integer(1))
+
+	tests := []struct {
+		name string
+		args []Object
+	}{
+		{"get", []Object{v, Integer(1)}},
+		{"get", []Object{v, Integer(-1)}},
+		{"get", []Object{NewList(), Integer(0)}},
+		{"get", []Object{Integer(1), Integer(0)}},
+		{"get", []Object{v, String("a")}},
+		{"set", []Object{v, Integer(1), Integer(0)}},
+		{"set", []Object{NewList(), Integer(0), Integer(0)}},
+		{"head", []Object{EmptyCons}},
+		{"tail", []Object{EmptyCons}},
+		{"head", []Object{v}},
+		{"cons", []Object{Integer(1), NewList()}},
+		{"to_list", []Object{Integer(1)}},
+	}
+
+	for _, tt := range tests {
+		if got := callBuiltin(t, tt.name, tt.args...); got.Type() != ErrorType {
+			t.Errorf("%s%v = %v, want an error", tt.name, tt.args, got)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package patukek_obj
+
+import "math"
+
+var mathBuiltins = []BuiltinImpl{
+	{
+		Name:      "pi",
+		Signature: "pi float",
+		Type:      "float",
+		Value:     Float(math.Pi),
+	},
+	{
+		Name:      "e",
+		Signature: "e float",
+		Type:      "float",
+		Value:     Float(math.E),
+	},
+	{
+		Name:      "abs",
+		Signature: "abs(x) number",
+		Type:      "patukek(number) -> number",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("abs: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			switch o := Unwrap(args[0]).(type) {
+			case Integer:
+				if o < 0 {
+					return -o
+				}
+				return o
+			case Float:
+				return Float(math.Abs(float64(o)))
+			default:
+				return NewError("abs: argument must be a number")
+			}
+		},
+	},
+	{
+		Name:      "min",
+		Signature: "min(xs...) number",
+		Type:      "patukek(any...) -> number",
+		Builtin: func(ctx Context, args ...Object) Object {
+			return extremum("min", args, numLess)
+		},
+	},
+	{
+		Name:      "max",
+		Signature: "max(xs...) number",
+		Type:      "patukek(any...) -> number",
+		Builtin: func(ctx Context, args ...Object) Object {
+			return extremum("max", args, func(a, b Object) bool { return numLess(b, a) })
+		},
+	},
+	{
+		Name:      "pow",
+		Signature: "pow(x, y) number",
+		Type:      "patukek(number, number) -> number",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("pow: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			base, bok := args[0].(Integer)
+			exp, eok := args[1].(Integer)
+			if bok && eok && exp >= 0 {
+				var ret Integer = 1
+				for ; exp > 0; exp >>= 1 {
+					if exp&1 == 1 {
+						ret *= base
+					}
+					base *= base
+				}
+				return ret
+			}
+
+			x, xok := ToFloat(args[0])
+			y, yok := ToFloat(args[1])
+			if !xok || !yok {
+				return NewError("pow: arguments must be numbers")
+			}
+			return Float(math.Pow(x, y))
+		},
+	},
+	{
+		Name:      "sqrt",
+		Signature: "sqrt(x) float",
+		Type:      "patukek(number) -> float",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("sqrt: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			x, ok := ToFloat(Unwrap(args[0]))
+			if !ok {
+				return NewError("sqrt: argument must be a number")
+			}
+			if x < 0 {
+				return NewError("sqrt: negative argument %v", args[0])
+			}
+			return Float(math.Sqrt(x))
+		},
+	},
+	{
+		Name:      "floor",
+		Signature: "floor(x) int",
+		Type:      "patukek(number) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("floor: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			switch o := Unwrap(args[0]).(type) {
+			case Integer:
+				return o
+			case Float:
+				return toInteger("floor", math.Floor(float64(o)))
+			default:
+				return NewError("floor: argument must be a number")
+			}
+		},
+	},
+	{
+		Name:      "ceil",
+		Signature: "ceil(x) int",
+		Type:      "patukek(number) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("ceil: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			switch o := Unwrap(args[0]).(type) {
+			case Integer:
+				return o
+			case Float:
+				return toInteger("ceil", math.Ceil(float64(o)))
+			default:
+				return NewError("ceil: argument must be a number")
+			}
+		},
+	},
+	{
+		Name:      "gcd",
+		Signature: "gcd(a, b) int",
+		Type:      "patukek(int, int) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("gcd: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			a, aok := args[0].(Integer)
+			b, bok := args[1].(Integer)
+			if !aok || !bok {
+				return NewError("gcd: arguments must be ints")
+			}
+
+			for b != 0 {
+				a, b = b, a%b
+			}
+			if a < 0 {
+				return -a
+			}
+			return a
+		},
+	},
+	{
+		Name:      "clamp",
+		Signature: "clamp(x, lo, hi) number",
+		Type:      "patukek(number, number, number) -> number",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 3 {
+				return NewError("clamp: wrong number of arguments, expected 3, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			for _, a := range args {
+				if !AssertTypes(a, IntType, FloatType) {
+					return NewError("clamp: arguments must be numbers")
+				}
+			}
+
+			x, lo, hi := args[0], args[1], args[2]
+			switch {
+			case numLess(hi, lo):
+				return NewError("clamp: lower bound %v is greater than upper bound %v", lo, hi)
+			case numLess(x, lo):
+				return lo
+			case numLess(hi, x):
+				return hi
+			default:
+				return x
+			}
+		},
+	},
+	{
+		Name:      "random",
+		Signature: "random() float",
+		Type:      "patukek() -> float",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 0 {
+				return NewError("random: wrong number of arguments, expected 0, got %d", l)
+			}
+			return Float(ctx.Rand().Float64())
+		},
+	},
+	{
+		Name:      "randint",
+		Signature: "randint(lo, hi) int",
+		Type:      "patukek(int, int) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("randint: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			lo, lok := args[0].(Integer)
+			hi, hok := args[1].(Integer)
+			if !lok || !hok {
+				return NewError("randint: arguments must be ints")
+			}
+			if lo > hi {
+				return NewError("randint: empty range [%d, %d]", lo, hi)
+			}
+			n := uint64(hi-lo) + 1
+			if n == 0 {
+				return NewError("randint: range [%d, %d] is too large", lo, hi)
+			}
+			return lo + Integer(ctx.Rand().Uint64N(n))
+		},
+	},
+	{
+		Name:      "seed",
+		Signature: "seed(n)",
+		Type:      "patukek(int) -> null",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("seed: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			s, ok := Unwrap(args[0]).(Integer)
+			if !ok {
+				return NewError("seed: argument must be an int")
+			}
+			ctx.Seed(uint64(s))
+			return NullObj
+		},
+	},
+}
+
+func numLess(a, b Object) bool {
+	if x, ok := a.(Integer); ok {
+		if y, ok := b.(Integer); ok {
+			return x < y
+		}
+	}
+
+	x, _ := ToFloat(a)
+	y, _ := ToFloat(b)
+	return x < y
+}
+
+// toInteger converts the whole float f to an int, failing when it is out of
+// range.
+func toInteger(name string, f float64) Object {
+	if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return NewError("%s: %v is out of the int range", name, Float(f))
+	}
+	return Integer(f)
+}
+
+func extremum(name string, args []Object, better func(a, b Object) bool) Object {
+	args = UnwrapAll(args)
+	if len(args) == 1 {
+		if l, ok := args[0].(List); ok {
+			args = UnwrapAll(append([]Object{}, l...))
+		}
+	}
+
+	if len(args) == 0 {
+		return NewError("%s: no argument provided", name)
+	}
+
+	ret := args[0]
+	for _, a := range args {
+		if !AssertTypes(a, IntType, FloatType) {
+			return NewError("%s: arguments must be numbers", name)
+		}
+		if better(a, ret) {
+			ret = a
+		}
+	}
+	return ret
+}
@@ -0,0 +1,87 @@
+package patukek_obj
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testContext is a Context that provides a random source, calls builtins
+// and has no size limit.
+type testContext struct {
+	Context
+	rand *rand.Rand
+}
+
+func newTestContext() testContext {
+	return testContext{rand: rand.New(rand.NewPCG(1, 2))}
+}
+
+func (c testContext) Rand() *rand.Rand {
+	return c.rand
+}
+
+func (c testContext) Call(fn Object, args ...Object) (Object, error) {
+	return fn.(Builtin)(c, args...), nil
+}
+
+func (c testContext) CheckSize(n int) error {
+	return nil
+}
+
+// callBuiltin calls the math, list or string builtin name.
+func callBuiltin(t *testing.T, name string, args ...Object) Object {
+	t.Helper()
+
+	for _, b := range slices.Concat(mathBuiltins, listBuiltins, stringBuiltins) {
+		if b.Name == name {
+			return b.Builtin(newTestContext(), args...)
+		}
+	}
+	t.Fatalf("no builtin %s", name)
+	return nil
+}
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		name string
+		args []Object
+		want Object
+	}{
+		{"floor", []Object{Float(2.5)}, Integer(2)},
+		{"floor", []Object{Float(-2.5)}, Integer(-3)},
+		{"ceil", []Object{Float(2.5)}, Integer(3)},
+		{"ceil", []Object{Float(math.MinInt64)}, Integer(math.MinInt64)},
+		{"randint", []Object{Integer(3), Integer(3)}, Integer(3)},
+	}
+
+	for _, tt := range tests {
+		if got := callBuiltin(t, tt.name, tt.args...); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []Object
+		want string
+	}{
+		{"floor", []Object{Float(1e300)}, "out of the int range"},
+		{"floor", []Object{Float(math.NaN())}, "out of the int range"},
+		{"ceil", []Object{Float(math.Inf(-1))}, "out of the int range"},
+		{"ceil", []Object{Float(math.MaxInt64)}, "out of the int range"},
+		{"randint", []Object{Integer(2), Integer(1)}, "empty range"},
+		{"randint", []Object{Integer(math.MinInt64), Integer(math.MaxInt64)}, "too large"},
+	}
+
+	for _, tt := range tests {
+		got := callBuiltin(t, tt.name, tt.args...)
+		if e, ok := got.(Error); !ok || !strings.Contains(string(e), tt.want) {
+			t.Errorf("%s%v = %v, want an error containing %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package patukek_obj
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func NewNativeFunc(name string, fn any) (Builtin, error) {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.IsNil() {
+		return nil, fmt.Errorf("%s: expected a function, got %T", name, fn)
+	}
+	if !supportedResults(f.Type()) {
+		return nil, fmt.Errorf("%s: unsupported results %v: expected T, (T, error) or error", name, f.Type())
+	}
+
+	return func(ctx Context, a ...Object) Object {
+		o := callNative(ctx, f, a...)
+		if e, ok := o.(Error); ok {
+			return NewError("%s: %s", name, e.Val())
+		}
+		return o
+	}, nil
+}
+
+// supportedResults reports whether the function type t returns a value, a
+// value and an error, or an error: the results that reach programs in full.
+func supportedResults(t reflect.Type) bool {
+	switch t.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return t.Out(1) == errorType && t.Out(0) != errorType
+	default:
+		return false
+	}
+}
@@ -1,6 +1,11 @@
 package patukek_obj
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+
+	"patukek/internal/patukek_suggest"
+)
 
 type NativeStruct struct {
 	s any
@@ -11,29 +16,83 @@ func NewNativeStruct(s any) Object {
 }
 
 func (n *NativeStruct) Get(name string) (Object, bool) {
-	return Builtin(func(a ...Object) (o Object) {
-		defer func() {
-			if err := recover(); err != nil {
-				o = NewError("%v", err)
-			}
-		}()
-
-		f := reflect.ValueOf(n.s).MethodByName(name)
-		arguments, err := args(f.Type(), a...)
-		if err != nil {
-			return NewError(err.Error())
+	if f := reflect.ValueOf(n.s).MethodByName(name); f.IsValid() {
+		return Builtin(func(ctx Context, a ...Object) Object {
+			return callNative(ctx, f, a...)
+		}), true
+	}
+
+	if f, ok := n.field(name); ok {
+		return toObject(f), true
+	}
+	return nil, false
+}
+
+func (n *NativeStruct) Set(name string, o Object) Object {
+	f, ok := n.field(name)
+	if !ok {
+		if s, ok := patukek_suggest.Closest(name, n.fields()); ok {
+			return NewError("%T has no field %s, did you mean %s?", n.s, name, s)
 		}
+		return NewError("%T has no field %s", n.s, name)
+	}
+	if !f.CanSet() {
+		return NewError("cannot assign to field %s of %T", name, n.s)
+	}
+
+	v, err := toValue(f.Type(), Unwrap(o))
+	if err != nil {
+		return NewError("%T.%s: %v", n.s, name, err)
+	}
+	f.Set(v)
+	return o
+}
 
-		return multiplex(f.Call(arguments))
-	}), true
+func (n *NativeStruct) field(name string) (reflect.Value, bool) {
+	v := reflect.Indirect(reflect.ValueOf(n.s))
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	sf, ok := v.Type().FieldByName(name)
+	if !ok || !sf.IsExported() {
+		return reflect.Value{}, false
+	}
+	f, err := v.FieldByIndexErr(sf.Index)
+	return f, err == nil
+}
+
+// Members returns the names of the exported methods and fields of the
+// struct.
+func (n *NativeStruct) Members() []string {
+	var (
+		ret []string
+		t   = reflect.TypeOf(n.s)
+	)
+
+	for i := 0; i < t.NumMethod(); i++ {
+		ret = append(ret, t.Method(i).Name)
+	}
+	return append(ret, n.fields()...)
 }
 
-func (n *NativeStruct) Set(string, Object) Object {
-	return NewError("cannot assign to native struct")
+func (n *NativeStruct) fields() []string {
+	var ret []string
+
+	v := reflect.Indirect(reflect.ValueOf(n.s))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if f.IsExported() && !f.Anonymous {
+			ret = append(ret, f.Name)
+		}
+	}
+	return ret
 }
 
 func (n *NativeStruct) String() string {
-	return "<native struct>"
+	return fmt.Sprintf("<native struct %T>", n.s)
 }
 
 func (n *NativeStruct) Type() Type {
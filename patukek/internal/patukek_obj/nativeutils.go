@@ -1,7 +1,9 @@
 package patukek_obj
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 )
 
@@ -14,78 +16,19 @@ func toValue(t reflect.Type, o Object) (reflect.Value, error) {
 		}
 		return reflect.ValueOf(p == True), nil
 
-	case reflect.Int:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected int but %v provided", o.Type())
-		}
-		return reflect.ValueOf(int(i)), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return toInt(t, o)
 
-	case reflect.Int8:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected int8 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(int8(i)), nil
-
-	case reflect.Int16:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected int16 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(int16(i)), nil
-
-	case reflect.Int32:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected int32 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(int32(i)), nil
-
-	case reflect.Int64:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected int64 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(int64(i)), nil
-
-	case reflect.Uint:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected uint but %v provided", o.Type())
-		}
-		return reflect.ValueOf(uint(i)), nil
-
-	case reflect.Uint8:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected uint8 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(uint8(i)), nil
-
-	case reflect.Uint16:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected uint16 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(uint16(i)), nil
-
-	case reflect.Uint32:
-		i, ok := o.(Integer)
-		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected uint32 but %v provided", o.Type())
-		}
-		return reflect.ValueOf(uint32(i)), nil
+	case reflect.Uintptr:
+		return reflect.Zero(t), fmt.Errorf("unsupported type 'uintptr'")
 
-	case reflect.Uint64:
-		i, ok := o.(Integer)
+	case reflect.Float32, reflect.Float64:
+		f, ok := ToFloat(o)
 		if !ok {
-			return reflect.Zero(t), fmt.Errorf("expected uint64 but %v provided", o.Type())
+			return reflect.Zero(t), fmt.Errorf("expected float but %v provided", o.Type())
 		}
-		return reflect.ValueOf(uint64(i)), nil
-
-	case reflect.Uintptr:
-		return reflect.Zero(t), fmt.Errorf("unsupported type 'uintptr'")
+		return reflect.ValueOf(f).Convert(t), nil
 
 	case reflect.Complex64:
 		return reflect.Zero(t), fmt.Errorf("unsupported type 'complex64'")
@@ -105,6 +48,9 @@ func toValue(t reflect.Type, o Object) (reflect.Value, error) {
 			return reflect.Zero(t), nil
 
 		default:
+			if t.NumMethod() == 0 {
+				return reflect.ValueOf(ToAny(o)), nil
+			}
 			return reflect.Zero(t), fmt.Errorf("unsupported type 'interface'")
 		}
 
@@ -178,8 +124,44 @@ func toValue(t reflect.Type, o Object) (reflect.Value, error) {
 	}
 }
 
-func args(t reflect.Type, a ...Object) (args []reflect.Value, err error) {
-	if t.NumIn() != len(a) {
+// toInt converts o to the integer type t, failing when o is not an int or
+// is out of the range of t.
+func toInt(t reflect.Type, o Object) (reflect.Value, error) {
+	i, ok := o.(Integer)
+	if !ok {
+		return reflect.Zero(t), fmt.Errorf("expected %v but %v provided", t.Kind(), o.Type())
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return reflect.Zero(t), fmt.Errorf("%d overflows %v", i, t.Kind())
+		}
+		v.SetUint(uint64(i))
+
+	default:
+		if v.OverflowInt(int64(i)) {
+			return reflect.Zero(t), fmt.Errorf("%d overflows %v", i, t.Kind())
+		}
+		v.SetInt(int64(i))
+	}
+	return v, nil
+}
+
+func args(ctx Context, t reflect.Type, a ...Object) (args []reflect.Value, err error) {
+	var nfixed = t.NumIn()
+
+	if t.IsVariadic() {
+		nfixed--
+		if len(a) < nfixed {
+			return args, fmt.Errorf(
+				"arguments mismatch: at least %d expected, %d provided",
+				nfixed,
+				len(a),
+			)
+		}
+	} else if nfixed != len(a) {
 		return args, fmt.Errorf(
 			"arguments mismatch: %d expected, %d provided",
 			t.NumIn(),
@@ -187,14 +169,118 @@ func args(t reflect.Type, a ...Object) (args []reflect.Value, err error) {
 		)
 	}
 
-	args = make([]reflect.Value, t.NumIn())
-	for i := 0; i < t.NumIn() && err == nil; i++ {
-		args[i], err = toValue(t.In(i), a[i])
+	args = make([]reflect.Value, len(a))
+	for i := 0; i < len(a) && err == nil; i++ {
+		in := t.In(min(i, nfixed))
+		if i >= nfixed {
+			in = in.Elem()
+		}
+
+		if in.Kind() == reflect.Func {
+			args[i], err = toFunc(ctx, in, Unwrap(a[i]))
+		} else {
+			args[i], err = toValue(in, Unwrap(a[i]))
+		}
 	}
 	return
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func toFunc(ctx Context, t reflect.Type, o Object) (reflect.Value, error) {
+	switch o.(type) {
+	case *Null:
+		return reflect.Zero(t), nil
+	case *Closure, Builtin:
+	default:
+		return reflect.Zero(t), fmt.Errorf("expected function but %v provided", o.Type())
+	}
+
+	var (
+		nout     = t.NumOut()
+		hasError = nout > 0 && t.Out(nout-1) == errorType
+	)
+
+	if nout > 2 || (nout == 2 && !hasError) {
+		return reflect.Zero(t), fmt.Errorf("unsupported function type %v", t)
+	}
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		var (
+			a   = make([]Object, len(in))
+			out = make([]reflect.Value, nout)
+		)
+
+		for i, v := range in {
+			a[i] = toObject(v)
+		}
+
+		ret, err := ctx.Call(o, a...)
+		if err != nil && !hasError {
+			panic(callbackError{err})
+		}
+		if err == nil && isError(ret) {
+			err = errors.New(ret.(Error).Val())
+		}
+		if err != nil && !hasError {
+			panic(err)
+		}
+
+		for i := 0; i < nout; i++ {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		if hasError && err != nil {
+			out[nout-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+
+		if nout == 2 || (nout == 1 && !hasError) {
+			v, err := toValue(t.Out(0), Unwrap(ret))
+			if err != nil {
+				panic(err)
+			}
+			out[0] = v
+		}
+		return out
+	}), nil
+}
+
+// callbackError is the error of a patukek function called by a Go function
+// that cannot return it. callNative stops the program with it, as the list
+// builtins do with the errors of their callbacks.
+type callbackError struct {
+	err error
+}
+
+func callNative(ctx Context, f reflect.Value, a ...Object) (o Object) {
+	defer func() {
+		switch err := recover().(type) {
+		case nil:
+		case callbackError:
+			o = NewRuntimeError(err.err)
+		default:
+			o = NewError("%v", err)
+		}
+	}()
+
+	arguments, err := args(ctx, f.Type(), a...)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	return multiplex(f.Call(arguments))
+}
+
 func toObject(v reflect.Value) Object {
+	if !v.IsValid() {
+		return NullObj
+	}
+	if v.CanInterface() {
+		if o, ok := v.Interface().(Object); ok {
+			return o
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.String:
 		return NewString(v.String())
@@ -206,8 +292,14 @@ func toObject(v reflect.Value) Object {
 		return NewInteger(v.Int())
 
 	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		if u := v.Uint(); u > math.MaxInt64 {
+			return NewError("%d overflows int", u)
+		}
 		return NewInteger(int64(v.Uint()))
 
+	case reflect.Float32, reflect.Float64:
+		return NewFloat(v.Float())
+
 	case reflect.Slice, reflect.Array:
 		l := make(List, v.Len())
 
@@ -217,20 +309,62 @@ func toObject(v reflect.Value) Object {
 		return l
 
 	case reflect.Struct, reflect.Ptr:
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return NullObj
+		}
 		return NewNativeStruct(v.Interface())
 
 	case reflect.Interface:
-		err, ok := v.Interface().(error)
-		if ok && err != nil {
-			return NewError(err.Error())
-		} else if err == nil {
+		if v.IsNil() {
 			return NullObj
 		}
-		fallthrough
+		if err, ok := v.Interface().(error); ok {
+			return NewError(err.Error())
+		}
+		return toObject(v.Elem())
+
+	default:
+		return NewError("unsupported type %v", v.Type())
+	}
+}
+
+func ToValue(t reflect.Type, o Object) (reflect.Value, error) {
+	return toValue(t, Unwrap(o))
+}
+
+func ToObject(v any) Object {
+	return toObject(reflect.ValueOf(v))
+}
 
+func ToAny(o Object) any {
+	switch o := Unwrap(o).(type) {
+	case *Null:
+		return nil
+	case *Boolean:
+		return o == True
+	case Integer:
+		return int64(o)
+	case Float:
+		return float64(o)
+	case String:
+		return string(o)
+	case List:
+		return toAnyElems(o)
+	case *Vector:
+		return toAnyElems(o.Elems())
+	case *Cons:
+		return toAnyElems(o.Elems())
 	default:
-		return NewError("unsupported type %T", v.Interface())
+		return o
+	}
+}
+
+func toAnyElems(elems []Object) []any {
+	ret := make([]any, len(elems))
+	for i, e := range elems {
+		ret[i] = ToAny(e)
 	}
+	return ret
 }
 
 func isError(o Object) bool {
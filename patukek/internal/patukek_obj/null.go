@@ -12,4 +12,7 @@ func (n Null) String() string {
 
 func (n Null) Type() Type {
 	return NullType
-}
\ No newline at end of file
+}
+func (n Null) KeyHash() KeyHash {
+	return KeyHash{Type: NullType}
+}
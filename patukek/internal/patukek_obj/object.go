@@ -49,8 +49,34 @@ const (
 	ListType
 	ContinueType
 	BreakType
+	FloatType
+	VectorType
+	ConsType
 )
 
+var typeNames = map[Type]string{
+	NullType:     "null",
+	ErrorType:    "error",
+	IntType:      "int",
+	BoolType:     "bool",
+	StringType:   "string",
+	ObjectType:   "object",
+	ReturnType:   "return",
+	FunctionType: "function",
+	ClosureType:  "closure",
+	BuiltinType:  "builtin",
+	ListType:     "list",
+	ContinueType: "continue",
+	BreakType:    "break",
+	FloatType:    "float",
+	VectorType:   "vector",
+	ConsType:     "cons",
+}
+
+func (t Type) String() string {
+	return typeNames[t]
+}
+
 var (
 	NullObj = NewNull()
 	True    = NewBoolean(true)
@@ -86,6 +112,8 @@ func IsTruthy(o Object) bool {
 		return o == True
 	case Integer:
 		return val != 0
+	case Float:
+		return val != 0
 	case *Null:
 		return false
 	default:
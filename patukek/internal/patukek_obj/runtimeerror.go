@@ -0,0 +1,17 @@
+package patukek_obj
+
+type RuntimeError struct {
+	Err error
+}
+
+func NewRuntimeError(err error) Object {
+	return &RuntimeError{Err: err}
+}
+
+func (r *RuntimeError) Type() Type {
+	return ErrorType
+}
+
+func (r *RuntimeError) String() string {
+	return r.Err.Error()
+}
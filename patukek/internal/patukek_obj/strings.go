@@ -0,0 +1,301 @@
+package patukek_obj
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var stringBuiltins = []BuiltinImpl{
+	{
+		Name:      "split",
+		Signature: "split(s[, sep]) list",
+		Type:      "patukek(string, [string]) -> list[string]",
+		Builtin: func(ctx Context, args ...Object) Object {
+			var parts []string
+
+			args = UnwrapAll(args)
+			switch l := len(args); l {
+			case 1:
+				s, ok := args[0].(String)
+				if !ok {
+					return NewError("split: first argument must be a string")
+				}
+				parts = strings.Fields(string(s))
+
+			case 2:
+				s, ok := args[0].(String)
+				if !ok {
+					return NewError("split: first argument must be a string")
+				}
+				sep, ok := args[1].(String)
+				if !ok {
+					return NewError("split: second argument must be a string")
+				}
+				parts = strings.Split(string(s), string(sep))
+
+			default:
+				return NewError("split: wrong number of arguments, expected 1 or 2, got %d", l)
+			}
+
+			ret := make(List, len(parts))
+			for i, p := range parts {
+				ret[i] = NewString(p)
+			}
+			return ret
+		},
+	},
+	{
+		Name:      "join",
+		Signature: "join(l, sep) string",
+		Type:      "patukek(list[any], string) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("join: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			lst, ok := args[0].(List)
+			if !ok {
+				return NewError("join: first argument must be a list")
+			}
+			sep, ok := args[1].(String)
+			if !ok {
+				return NewError("join: second argument must be a string")
+			}
+
+			parts := make([]string, len(lst))
+			for i, e := range lst {
+				parts[i] = Unwrap(e).String()
+			}
+			return NewString(strings.Join(parts, string(sep)))
+		},
+	},
+	{
+		Name:      "trim",
+		Signature: "trim(s[, cutset]) string",
+		Type:      "patukek(string, [string]) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			args = UnwrapAll(args)
+			switch l := len(args); l {
+			case 1:
+				s, ok := args[0].(String)
+				if !ok {
+					return NewError("trim: first argument must be a string")
+				}
+				return NewString(strings.TrimSpace(string(s)))
+
+			case 2:
+				s, ok := args[0].(String)
+				if !ok {
+					return NewError("trim: first argument must be a string")
+				}
+				cutset, ok := args[1].(String)
+				if !ok {
+					return NewError("trim: second argument must be a string")
+				}
+				return NewString(strings.Trim(string(s), string(cutset)))
+
+			default:
+				return NewError("trim: wrong number of arguments, expected 1 or 2, got %d", l)
+			}
+		},
+	},
+	{
+		Name:      "upper",
+		Signature: "upper(s) string",
+		Type:      "patukek(string) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("upper: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			s, ok := Unwrap(args[0]).(String)
+			if !ok {
+				return NewError("upper: argument must be a string")
+			}
+			return NewString(strings.ToUpper(string(s)))
+		},
+	},
+	{
+		Name:      "lower",
+		Signature: "lower(s) string",
+		Type:      "patukek(string) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 1 {
+				return NewError("lower: wrong number of arguments, expected 1, got %d", l)
+			}
+
+			s, ok := Unwrap(args[0]).(String)
+			if !ok {
+				return NewError("lower: argument must be a string")
+			}
+			return NewString(strings.ToLower(string(s)))
+		},
+	},
+	{
+		Name:      "replace",
+		Signature: "replace(s, old, new[, n]) string",
+		Type:      "patukek(string, string, string, [int]) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			var n = -1
+
+			args = UnwrapAll(args)
+			switch l := len(args); l {
+			case 3:
+
+			case 4:
+				i, ok := args[3].(Integer)
+				if !ok {
+					return NewError("replace: fourth argument must be an int")
+				}
+				n = int(i)
+
+			default:
+				return NewError("replace: wrong number of arguments, expected 3 or 4, got %d", l)
+			}
+
+			strs, err := stringArgs("replace", args[:3])
+			if err != nil {
+				return err
+			}
+			return NewString(strings.Replace(strs[0], strs[1], strs[2], n))
+		},
+	},
+	{
+		Name:      "contains",
+		Signature: "contains(s, substr) bool",
+		Type:      "patukek(string, string) -> bool",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("contains: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			strs, err := stringArgs("contains", UnwrapAll(args))
+			if err != nil {
+				return err
+			}
+			return ParseBool(strings.Contains(strs[0], strs[1]))
+		},
+	},
+	{
+		Name:      "starts_with",
+		Signature: "starts_with(s, prefix) bool",
+		Type:      "patukek(string, string) -> bool",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("starts_with: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			strs, err := stringArgs("starts_with", UnwrapAll(args))
+			if err != nil {
+				return err
+			}
+			return ParseBool(strings.HasPrefix(strs[0], strs[1]))
+		},
+	},
+	{
+		Name:      "ends_with",
+		Signature: "ends_with(s, suffix) bool",
+		Type:      "patukek(string, string) -> bool",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("ends_with: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			strs, err := stringArgs("ends_with", UnwrapAll(args))
+			if err != nil {
+				return err
+			}
+			return ParseBool(strings.HasSuffix(strs[0], strs[1]))
+		},
+	},
+	{
+		Name:      "index_of",
+		Signature: "index_of(s, substr) int",
+		Type:      "patukek(string, string) -> int",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("index_of: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			strs, err := stringArgs("index_of", UnwrapAll(args))
+			if err != nil {
+				return err
+			}
+			return Integer(strings.Index(strs[0], strs[1]))
+		},
+	},
+	{
+		Name:      "repeat",
+		Signature: "repeat(s, n) string",
+		Type:      "patukek(string, int) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if l := len(args); l != 2 {
+				return NewError("repeat: wrong number of arguments, expected 2, got %d", l)
+			}
+
+			args = UnwrapAll(args)
+			s, ok := args[0].(String)
+			if !ok {
+				return NewError("repeat: first argument must be a string")
+			}
+			n, ok := args[1].(Integer)
+			if !ok {
+				return NewError("repeat: second argument must be an int")
+			}
+			if n < 0 {
+				return NewError("repeat: negative count %d", n)
+			}
+			if n > 0 && len(s) > math.MaxInt/int(n) {
+				return NewError("repeat: result too long")
+			}
+			if err := ctx.CheckSize(len(s) * int(n)); err != nil {
+				return NewRuntimeError(err)
+			}
+			return NewString(strings.Repeat(string(s), int(n)))
+		},
+	},
+	{
+		Name:      "format",
+		Signature: "format(fmt, args...) string",
+		Type:      "patukek(string, any...) -> string",
+		Builtin: func(ctx Context, args ...Object) Object {
+			if len(args) == 0 {
+				return NewError("format: no argument provided")
+			}
+
+			args = UnwrapAll(args)
+			f, ok := args[0].(String)
+			if !ok {
+				return NewError("format: first argument must be a string")
+			}
+
+			values := make([]any, len(args)-1)
+			for i, a := range args[1:] {
+				if b, ok := a.(*Boolean); ok {
+					values[i] = bool(*b)
+				} else {
+					values[i] = a
+				}
+			}
+			return NewString(fmt.Sprintf(string(f), values...))
+		},
+	},
+}
+
+func stringArgs(name string, args []Object) ([]string, Object) {
+	var (
+		ret     = make([]string, len(args))
+		ordinal = []string{"first", "second", "third"}
+	)
+
+	for i, a := range args {
+		s, ok := a.(String)
+		if !ok {
+			return nil, NewError("%s: %s argument must be a string", name, ordinal[i])
+		}
+		ret[i] = string(s)
+	}
+	return ret, nil
+}
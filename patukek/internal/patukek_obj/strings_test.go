@@ -0,0 +1,110 @@
+package patukek_obj
+
+import "testing"
+
+func strs(s ...string) List {
+	ret := make(List, len(s))
+	for i, e := range s {
+		ret[i] = String(e)
+	}
+	return ret
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		args []Object
+		want Object
+	}{
+		{"split", []Object{String(" a  b\tc\n")}, strs("a", "b", "c")},
+		{"split", []Object{String("")}, strs()},
+		{"split", []Object{String("a,b,,c"), String(",")}, strs("a", "b", "", "c")},
+		{"split", []Object{String(""), String(",")}, strs("")},
+		{"split", []Object{String("аб"), String("")}, strs("а", "б")},
+
+		{"join", []Object{strs("a", "b"), String(", ")}, String("a, b")},
+		{"join", []Object{NewList(Integer(1), String("б"), True), String("-")}, String("1-б-true")},
+		{"join", []Object{strs(), String(",")}, String("")},
+
+		{"trim", []Object{String(" \tпривет \n")}, String("привет")},
+		{"trim", []Object{String("")}, String("")},
+		{"trim", []Object{String("--a-b--"), String("-")}, String("a-b")},
+		{"trim", []Object{String("«цитата»"), String("«»")}, String("цитата")},
+
+		{"upper", []Object{String("Hello, мир")}, String("HELLO, МИР")},
+		{"upper", []Object{String("")}, String("")},
+		{"lower", []Object{String("Hello, МИР")}, String("hello, мир")},
+		{"lower", []Object{String("")}, String("")},
+
+		{"replace", []Object{String("a.b.c"), String("."), String("/")}, String("a/b/c")},
+		{"replace", []Object{String("a.b.c"), String("."), String("/"), Integer(1)}, String("a/b.c")},
+		{"replace", []Object{String("ёж"), String("ё"), String("е")}, String("еж")},
+		{"replace", []Object{String(""), String("a"), String("b")}, String("")},
+
+		{"contains", []Object{String("привет"), String("иве")}, True},
+		{"contains", []Object{String("abc"), String("")}, True},
+		{"contains", []Object{String(""), String("a")}, False},
+
+		{"starts_with", []Object{String("привет"), String("при")}, True},
+		{"starts_with", []Object{String("abc"), String("b")}, False},
+		{"starts_with", []Object{String(""), String("")}, True},
+		{"ends_with", []Object{String("привет"), String("вет")}, True},
+		{"ends_with", []Object{String("abc"), String("b")}, False},
+		{"ends_with", []Object{String(""), String("")}, True},
+
+		// Indexes count bytes, as len does.
+		{"index_of", []Object{String("abc"), String("c")}, Integer(2)},
+		{"index_of", []Object{String("привет"), String("в")}, Integer(6)},
+		{"index_of", []Object{String("abc"), String("d")}, Integer(-1)},
+		{"index_of", []Object{String(""), String("")}, Integer(0)},
+
+		{"repeat", []Object{String("ab"), Integer(3)}, String("ababab")},
+		{"repeat", []Object{String("я"), Integer(2)}, String("яя")},
+		{"repeat", []Object{String("ab"), Integer(0)}, String("")},
+		{"repeat", []Object{String(""), Integer(5)}, String("")},
+
+		{"format", []Object{String("%d-%s %v"), Integer(1), String("б"), True}, String("1-б true")},
+		{"format", []Object{String("%.2f%%"), Float(12.345)}, String("12.35%")},
+		{"format", []Object{String("")}, String("")},
+	}
+
+	for _, tt := range tests {
+		if got := callBuiltin(t, tt.name, tt.args...); !Equal(got, tt.want) {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []Object
+		want string
+	}{
+		{"split", []Object{Integer(1)}, "split: first argument must be a string"},
+		{"split", []Object{String("a"), Integer(1)}, "split: second argument must be a string"},
+		{"split", nil, "split: wrong number of arguments, expected 1 or 2, got 0"},
+		{"join", []Object{String("a"), String(",")}, "join: first argument must be a list"},
+		{"join", []Object{strs()}, "join: wrong number of arguments, expected 2, got 1"},
+		{"trim", []Object{String("a"), Integer(1)}, "trim: second argument must be a string"},
+		{"upper", []Object{Integer(1)}, "upper: argument must be a string"},
+		{"lower", nil, "lower: wrong number of arguments, expected 1, got 0"},
+		{"replace", []Object{String("a"), String("a"), Integer(1)}, "replace: third argument must be a string"},
+		{"replace", []Object{String("a"), String("a"), String("b"), String("1")}, "replace: fourth argument must be an int"},
+		{"contains", []Object{String("a"), Integer(1)}, "contains: second argument must be a string"},
+		{"starts_with", []Object{Integer(1), String("a")}, "starts_with: first argument must be a string"},
+		{"ends_with", []Object{String("a")}, "ends_with: wrong number of arguments, expected 2, got 1"},
+		{"index_of", []Object{String("a"), Float(1)}, "index_of: second argument must be a string"},
+		{"repeat", []Object{String("a"), Integer(-1)}, "repeat: negative count -1"},
+		{"repeat", []Object{String("ab"), Integer(1 << 62)}, "repeat: result too long"},
+		{"format", nil, "format: no argument provided"},
+		{"format", []Object{Integer(1)}, "format: first argument must be a string"},
+	}
+
+	for _, tt := range tests {
+		got := callBuiltin(t, tt.name, tt.args...)
+		if e, ok := got.(Error); !ok || e.Val() != tt.want {
+			t.Errorf("%s%v = %v, want the error %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
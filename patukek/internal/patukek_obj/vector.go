@@ -0,0 +1,170 @@
+package patukek_obj
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// Vector is a persistent vector implemented as a bit-partitioned trie
+// with a tail buffer. Append and Update return a new vector sharing most
+// of its structure with the receiver, which is never modified.
+type Vector struct {
+	cnt   int
+	shift uint
+	root  *vectorNode
+	tail  []Object
+}
+
+type vectorNode struct {
+	nodes []*vectorNode
+	elems []Object
+}
+
+var EmptyVector = &Vector{shift: vectorBits, root: &vectorNode{}}
+
+func NewVector(elems ...Object) *Vector {
+	v := EmptyVector
+	for _, e := range elems {
+		v = v.Append(e)
+	}
+	return v
+}
+
+func (v *Vector) Type() Type {
+	return VectorType
+}
+
+func (v *Vector) String() string {
+	return fmt.Sprintf("vector(%s)", joinElems(v.Elems()))
+}
+
+func (v *Vector) Len() int {
+	return v.cnt
+}
+
+func (v *Vector) Get(i int) (Object, bool) {
+	if i < 0 || i >= v.cnt {
+		return nil, false
+	}
+
+	if i >= v.tailOffset() {
+		return v.tail[i&vectorMask], true
+	}
+
+	node := v.root
+	for level := v.shift; level > 0; level -= vectorBits {
+		node = node.nodes[(i>>level)&vectorMask]
+	}
+	return node.elems[i&vectorMask], true
+}
+
+func (v *Vector) Append(o Object) *Vector {
+	if v.cnt-v.tailOffset() < vectorWidth {
+		tail := make([]Object, len(v.tail)+1)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = o
+		return &Vector{cnt: v.cnt + 1, shift: v.shift, root: v.root, tail: tail}
+	}
+
+	var (
+		leaf  = &vectorNode{elems: v.tail}
+		root  *vectorNode
+		shift = v.shift
+	)
+
+	if v.cnt>>vectorBits > 1<<v.shift {
+		root = &vectorNode{nodes: []*vectorNode{v.root, newVectorPath(v.shift, leaf)}}
+		shift += vectorBits
+	} else {
+		root = v.pushTail(v.shift, v.root, leaf)
+	}
+	return &Vector{cnt: v.cnt + 1, shift: shift, root: root, tail: []Object{o}}
+}
+
+func (v *Vector) Update(i int, o Object) (*Vector, bool) {
+	if i < 0 || i >= v.cnt {
+		return nil, false
+	}
+
+	if i >= v.tailOffset() {
+		tail := slices.Clone(v.tail)
+		tail[i&vectorMask] = o
+		return &Vector{cnt: v.cnt, shift: v.shift, root: v.root, tail: tail}, true
+	}
+	return &Vector{cnt: v.cnt, shift: v.shift, root: updateVectorNode(v.shift, v.root, i, o), tail: v.tail}, true
+}
+
+func (v *Vector) Elems() []Object {
+	ret := make([]Object, 0, v.cnt)
+
+	var walk func(n *vectorNode)
+	walk = func(n *vectorNode) {
+		ret = append(ret, n.elems...)
+		for _, c := range n.nodes {
+			walk(c)
+		}
+	}
+
+	walk(v.root)
+	return append(ret, v.tail...)
+}
+
+func (v *Vector) tailOffset() int {
+	if v.cnt < vectorWidth {
+		return 0
+	}
+	return ((v.cnt - 1) >> vectorBits) << vectorBits
+}
+
+func (v *Vector) pushTail(level uint, parent, leaf *vectorNode) *vectorNode {
+	var (
+		idx   = ((v.cnt - 1) >> level) & vectorMask
+		nodes = slices.Clone(parent.nodes)
+		child *vectorNode
+	)
+
+	switch {
+	case level == vectorBits:
+		child = leaf
+	case idx < len(nodes):
+		child = v.pushTail(level-vectorBits, nodes[idx], leaf)
+	default:
+		child = newVectorPath(level-vectorBits, leaf)
+	}
+
+	if idx < len(nodes) {
+		nodes[idx] = child
+	} else {
+		nodes = append(nodes, child)
+	}
+	return &vectorNode{nodes: nodes}
+}
+
+func newVectorPath(level uint, n *vectorNode) *vectorNode {
+	if level == 0 {
+		return n
+	}
+	return &vectorNode{nodes: []*vectorNode{newVectorPath(level-vectorBits, n)}}
+}
+
+func updateVectorNode(level uint, n *vectorNode, i int, o Object) *vectorNode {
+	if level == 0 {
+		elems := slices.Clone(n.elems)
+		elems[i&vectorMask] = o
+		return &vectorNode{elems: elems}
+	}
+
+	var (
+		idx   = (i >> level) & vectorMask
+		nodes = slices.Clone(n.nodes)
+	)
+
+	nodes[idx] = updateVectorNode(level-vectorBits, nodes[idx], i, o)
+	return &vectorNode{nodes: nodes}
+}
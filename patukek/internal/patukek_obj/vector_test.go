@@ -0,0 +1,114 @@
+package patukek_obj
+
+import (
+	"fmt"
+	"testing"
+)
+
+// vectorSizes cross the boundaries of the tail and of each level of the
+// trie: 32 elements fill the tail, 33 push it into the root, 1024 + 32 fill
+// a root of depth one and 32768 + 32 one of depth two.
+var vectorSizes = []int{0, 1, 31, 32, 33, 64, 65, 1024, 1056, 1057, 1088, 32800, 32801}
+
+func intVector(n int) *Vector {
+	v := EmptyVector
+	for i := 0; i < n; i++ {
+		v = v.Append(Integer(i))
+	}
+	return v
+}
+
+func checkVector(t *testing.T, v *Vector, want func(i int) Object, n int) {
+	t.Helper()
+
+	if v.Len() != n {
+		t.Fatalf("Len() = %d, want %d", v.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		if got, ok := v.Get(i); !ok || !Equal(got, want(i)) {
+			t.Fatalf("Get(%d) = %v, %v, want %v", i, got, ok, want(i))
+		}
+	}
+
+	elems := v.Elems()
+	if len(elems) != n {
+		t.Fatalf("len(Elems()) = %d, want %d", len(elems), n)
+	}
+	for i, e := range elems {
+		if !Equal(e, want(i)) {
+			t.Fatalf("Elems()[%d] = %v, want %v", i, e, want(i))
+		}
+	}
+
+	for _, i := range []int{-1, n} {
+		if got, ok := v.Get(i); ok {
+			t.Fatalf("Get(%d) = %v, want it out of range", i, got)
+		}
+		if _, ok := v.Update(i, Integer(0)); ok {
+			t.Fatalf("Update(%d) succeeded, want it out of range", i)
+		}
+	}
+}
+
+func TestVectorAppend(t *testing.T) {
+	for _, n := range vectorSizes {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			checkVector(t, intVector(n), func(i int) Object { return Integer(i) }, n)
+		})
+	}
+}
+
+func TestVectorAppendShared(t *testing.T) {
+	v := intVector(1056)
+	a, b := v.Append(String("a")), v.Append(String("b"))
+
+	if got, _ := a.Get(1056); !Equal(got, String("a")) {
+		t.Errorf("a.Get(1056) = %v, want a", got)
+	}
+	if got, _ := b.Get(1056); !Equal(got, String("b")) {
+		t.Errorf("b.Get(1056) = %v, want b", got)
+	}
+	checkVector(t, v, func(i int) Object { return Integer(i) }, 1056)
+}
+
+func TestVectorUpdate(t *testing.T) {
+	for _, n := range vectorSizes {
+		if n == 0 {
+			continue
+		}
+
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			old := intVector(n)
+
+			// Update every index, in the trie and in the tail, and check
+			// that no version sees the updates made after it.
+			versions := []*Vector{old}
+			for i := 0; i < n; i += max(1, n/40) {
+				v, ok := versions[len(versions)-1].Update(i, Integer(-i))
+				if !ok {
+					t.Fatalf("Update(%d) failed", i)
+				}
+				versions = append(versions, v)
+			}
+
+			checkVector(t, old, func(i int) Object { return Integer(i) }, n)
+
+			last := versions[len(versions)-1]
+			checkVector(t, last, func(i int) Object {
+				if i%max(1, n/40) == 0 {
+					return Integer(-i)
+				}
+				return Integer(i)
+			}, n)
+		})
+	}
+}
+
+func TestVectorString(t *testing.T) {
+	if got, want := NewVector(Integer(1), String("a")).String(), `vector(1, "a")`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	if got, want := EmptyVector.String(), "vector()"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package patukek_parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func benchInput(b *testing.B) string {
+	files, err := filepath.Glob("../../examples/*.ptk")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var buf strings.Builder
+	for _, path := range files {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		buf.Write(src)
+		buf.WriteByte('\n')
+	}
+	return strings.Repeat(buf.String(), 20)
+}
+
+func BenchmarkParse(b *testing.B) {
+	src := benchInput(b)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, errs := Parse("bench.ptk", src); len(errs) > 0 {
+			b.Fatal(errs[0])
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package patukek_parser
+
+import (
+	"testing"
+
+	"patukek/internal/patukek_seed"
+)
+
+func FuzzParse(f *testing.F) {
+	patukek_seed.AddExamples(f)
+	f.Add(`println("{a + "{b}"}")`)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		tree, errs := Parse("fuzz.ptk", in)
+		if len(errs) == 0 {
+			_ = tree.String()
+		}
+	})
+}
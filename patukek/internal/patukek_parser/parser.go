@@ -1,10 +1,11 @@
 package patukek_parser
 
 import (
+	"errors"
 	"patukek/internal/patukek_ast/calc_ops"
 	"patukek/internal/patukek_ast/logic_ops"
-	"errors"
 	"strconv"
+	"strings"
 
 	"patukek/internal/patukek_ast"
 	"patukek/internal/patukek_err"
@@ -13,13 +14,15 @@ import (
 )
 
 type Parser struct {
-	items         chan patukek_item.Item
+	lex           *patukek_lexer.Lexer
 	file          string
 	input         string
 	prefixParsers map[patukek_item.Type]parsePrefixFn
 	infixParsers  map[patukek_item.Type]parseInfixFn
 	cur           patukek_item.Item
 	peek          patukek_item.Item
+	curDoc        []string
+	peekDoc       []string
 	errs          []error
 	nestedLoops   uint
 }
@@ -60,26 +63,30 @@ var precedences = map[patukek_item.Type]int{
 	patukek_item.Asterisk:      Multiplicative,
 	patukek_item.LParen:        Call,
 	patukek_item.LBracket:      Index,
+	patukek_item.Dot:           Index,
 }
 
-func newParser(file, input string, items chan patukek_item.Item) *Parser {
+func newParser(file, input string) *Parser {
 	p := &Parser{
-		cur:           <-items,
-		peek:          <-items,
-		items:         items,
+		lex:           patukek_lexer.New(input),
 		file:          file,
 		input:         input,
 		prefixParsers: make(map[patukek_item.Type]parsePrefixFn),
 		infixParsers:  make(map[patukek_item.Type]parseInfixFn),
 	}
+	p.cur, p.curDoc = p.read()
+	p.peek, p.peekDoc = p.read()
+
 	p.registerPrefix(patukek_item.Ident, p.parseIdentifier)
 	p.registerPrefix(patukek_item.Int, p.parseInteger)
+	p.registerPrefix(patukek_item.Float, p.parseFloat)
 	p.registerPrefix(patukek_item.String, p.parseString)
 	p.registerPrefix(patukek_item.LParen, p.parseGroupedExpr)
 	p.registerPrefix(patukek_item.If, p.parseIfExpr)
 	p.registerPrefix(patukek_item.Function, p.parseFunction)
 	p.registerPrefix(patukek_item.LBracket, p.parseList)
 	p.registerPrefix(patukek_item.Error, p.parseError)
+	p.registerPrefix(patukek_item.Minus, p.parseNegative)
 
 	p.registerInfix(patukek_item.Equals, p.parseEquals)
 	p.registerInfix(patukek_item.NotEquals, p.parseNotEquals)
@@ -96,13 +103,33 @@ func newParser(file, input string, items chan patukek_item.Item) *Parser {
 	p.registerInfix(patukek_item.Modulus, p.parseModulus)
 	p.registerInfix(patukek_item.Assign, p.parseAssign)
 	p.registerInfix(patukek_item.LParen, p.parseCall)
+	p.registerInfix(patukek_item.Dot, p.parseDot)
 
 	return p
 }
 
 func (p *Parser) next() {
-	p.cur = p.peek
-	p.peek = <-p.items
+	p.cur, p.curDoc = p.peek, p.peekDoc
+	p.peek, p.peekDoc = p.read()
+}
+
+// read returns the next item from the lexer, skipping comments, together
+// with the text of the doc comments preceding it.
+func (p *Parser) read() (patukek_item.Item, []string) {
+	var doc []string
+
+	for {
+		i := p.lex.NextToken()
+		if i.Is(patukek_item.Comment) {
+			continue
+		}
+		if !i.Is(patukek_item.DocComment) {
+			return i, doc
+		}
+
+		text := strings.TrimPrefix(i.Val, "///")
+		doc = append(doc, strings.TrimPrefix(strings.TrimRight(text, " \t\r"), " "))
+	}
 }
 
 func (p *Parser) errors() []error {
@@ -110,11 +137,15 @@ func (p *Parser) errors() []error {
 }
 
 func (p *Parser) errorf(s string, a ...any) {
-	p.errs = append(p.errs, patukek_err.New(p.file, p.input, p.cur.Pos, s, a...))
+	end := p.cur.Pos + len(p.cur.Val)
+	if p.cur.Is(patukek_item.Error) || p.cur.Is(patukek_item.String) {
+		end = p.cur.Pos + 1
+	}
+	p.errs = append(p.errs, patukek_err.NewSpan(p.file, p.input, p.cur.Pos, end, s, a...))
 }
 
 func (p *Parser) parse() patukek_ast.Node {
-	var block = patukek_ast.NewBlock()
+	var block = patukek_ast.NewBlock(0)
 
 	for !p.cur.Is(patukek_item.EOF) {
 		if s := p.parseStatement(); s != nil {
@@ -122,6 +153,7 @@ func (p *Parser) parse() patukek_ast.Node {
 		}
 		p.next()
 	}
+	block.SetEnd(len(p.input))
 	return &block
 }
 
@@ -129,15 +161,34 @@ func (p *Parser) parseStatement() patukek_ast.Node {
 	if p.cur.Is(patukek_item.Return) {
 		return p.parseReturn()
 	}
-	return p.parseExpr(Lowest)
+
+	doc := strings.Join(p.curDoc, "\n")
+	switch n := p.parseExpr(Lowest).(type) {
+	case patukek_ast.Assign:
+		n.Doc = doc
+		return n
+	case patukek_ast.Function:
+		n.Doc = doc
+		return n
+	default:
+		return n
+	}
 }
 
 func (p *Parser) parseReturn() patukek_ast.Node {
 	var ret patukek_ast.Node
 
-	p.next()
-	if !p.cur.Is(patukek_item.Semicolon) {
-		ret = patukek_ast.NewReturn(p.parseExpr(Lowest), p.cur.Pos)
+	pos := p.cur.Pos
+	switch {
+	case p.peek.Is(patukek_item.RBrace) || p.peek.Is(patukek_item.EOF):
+		return patukek_ast.NewReturn(nil, pos)
+
+	case p.peek.Is(patukek_item.Semicolon):
+		ret = patukek_ast.NewReturn(nil, pos)
+
+	default:
+		p.next()
+		ret = patukek_ast.NewReturn(p.parseExpr(Lowest), pos)
 	}
 
 	if p.peek.Is(patukek_item.Semicolon) {
@@ -178,7 +229,7 @@ func (p *Parser) parseGroupedExpr() patukek_ast.Node {
 }
 
 func (p *Parser) parseBlock() patukek_ast.Node {
-	var block patukek_ast.Block
+	var block = patukek_ast.NewBlock(p.cur.Pos)
 	p.next()
 
 	for !p.cur.Is(patukek_item.RBrace) && !p.cur.Is(patukek_item.EOF) {
@@ -193,6 +244,7 @@ func (p *Parser) parseBlock() patukek_ast.Node {
 		return nil
 	}
 
+	block.SetEnd(p.cur.Pos + 1)
 	return &block
 }
 
@@ -226,8 +278,9 @@ func (p *Parser) parseIfExpr() patukek_ast.Node {
 }
 
 func (p *Parser) parseList() patukek_ast.Node {
+	pos := p.cur.Pos
 	nodes := p.parseNodeList(patukek_item.RBracket)
-	return patukek_ast.NewList(nodes...)
+	return patukek_ast.NewList(pos, p.cur.Pos+1, nodes...)
 }
 
 func (p *Parser) parseFunction() patukek_ast.Node {
@@ -237,11 +290,23 @@ func (p *Parser) parseFunction() patukek_ast.Node {
 	}
 
 	params := p.parseFunctionParams()
+
+	var result *patukek_ast.TypeExpr
+	if p.peek.Is(patukek_item.Arrow) {
+		p.next()
+		p.next()
+		if result = p.parseType(); result == nil {
+			return nil
+		}
+	}
+
 	if !p.expectPeek(patukek_item.LBrace) {
 		return nil
 	}
 
-	return patukek_ast.NewFunction(params, p.parseBlock(), pos)
+	fn := patukek_ast.NewFunction(params, p.parseBlock(), pos).(patukek_ast.Function)
+	fn.Result = result
+	return fn
 }
 
 func (p *Parser) parseFunctionParams() []patukek_ast.Identifier {
@@ -253,12 +318,12 @@ func (p *Parser) parseFunctionParams() []patukek_ast.Identifier {
 	}
 
 	p.next()
-	ret = append(ret, patukek_ast.NewIdentifier(p.cur.Val, p.cur.Pos))
+	ret = append(ret, p.parseParam())
 
 	for p.peek.Is(patukek_item.Comma) {
 		p.next()
 		p.next()
-		ret = append(ret, patukek_ast.NewIdentifier(p.cur.Val, p.cur.Pos))
+		ret = append(ret, p.parseParam())
 	}
 
 	if !p.expectPeek(patukek_item.RParen) {
@@ -267,12 +332,127 @@ func (p *Parser) parseFunctionParams() []patukek_ast.Identifier {
 	return ret
 }
 
+// parseParam parses a function parameter and its optional annotation.
+func (p *Parser) parseParam() patukek_ast.Identifier {
+	i := patukek_ast.NewIdentifier(p.cur.Val, p.cur.Pos)
+
+	if p.peek.Is(patukek_item.Colon) {
+		p.next()
+		p.next()
+		i.Type = p.parseType()
+	}
+	return i
+}
+
+// parseType parses the type annotation starting at the current item.
+func (p *Parser) parseType() *patukek_ast.TypeExpr {
+	t := patukek_ast.NewTypeExpr(p.cur.Val, p.cur.Pos, p.cur.Pos+len(p.cur.Val))
+
+	switch {
+	case p.cur.Is(patukek_item.Function):
+		if !p.expectPeek(patukek_item.LParen) {
+			return nil
+		}
+
+		if p.peek.Is(patukek_item.RParen) {
+			p.next()
+		} else {
+			for {
+				p.next()
+				param := p.parseParamType()
+				if param == nil {
+					return nil
+				}
+				t.Params = append(t.Params, param)
+
+				if !p.peek.Is(patukek_item.Comma) {
+					break
+				}
+				p.next()
+			}
+			if !p.expectPeek(patukek_item.RParen) {
+				return nil
+			}
+		}
+		t.SetEnd(p.cur.Pos + 1)
+
+		if p.peek.Is(patukek_item.Arrow) {
+			p.next()
+			p.next()
+			if t.Result = p.parseType(); t.Result == nil {
+				return nil
+			}
+			t.SetEnd(t.Result.End())
+		}
+
+	case p.cur.Is(patukek_item.Ident):
+		if p.peek.Is(patukek_item.LBracket) {
+			p.next()
+			p.next()
+			if t.Elem = p.parseType(); t.Elem == nil {
+				return nil
+			}
+			if !p.expectPeek(patukek_item.RBracket) {
+				return nil
+			}
+			t.SetEnd(p.cur.Pos + 1)
+		}
+
+	case p.cur.Is(patukek_item.Null):
+
+	default:
+		p.errorf("expected a type, got %v", p.cur.Typ)
+		return nil
+	}
+	return t
+}
+
+// parseParamType parses the type of a parameter of a function type,
+// which may be optional or variadic.
+func (p *Parser) parseParamType() *patukek_ast.TypeExpr {
+	if p.cur.Is(patukek_item.LBracket) {
+		p.next()
+		t := p.parseType()
+		if t == nil || !p.expectPeek(patukek_item.RBracket) {
+			return nil
+		}
+		t.Optional = true
+		return t
+	}
+
+	t := p.parseType()
+	if t != nil && p.peek.Is(patukek_item.Dot) {
+		for range 3 {
+			if !p.expectPeek(patukek_item.Dot) {
+				return nil
+			}
+		}
+		t.Variadic = true
+		t.SetEnd(p.cur.Pos + 1)
+	}
+	return t
+}
+
 func (p *Parser) parseIdentifier() patukek_ast.Node {
-	return patukek_ast.NewIdentifier(p.cur.Val, p.cur.Pos)
+	i := patukek_ast.NewIdentifier(p.cur.Val, p.cur.Pos)
+
+	if p.peek.Is(patukek_item.Colon) {
+		p.next()
+		p.next()
+		if i.Type = p.parseType(); i.Type == nil {
+			return nil
+		}
+		if !p.peek.Is(patukek_item.Assign) {
+			p.next()
+			p.errorf("a type annotation must be followed by an assignment")
+			return nil
+		}
+	}
+	return i
 }
 
 func (p *Parser) parseError() patukek_ast.Node {
-	p.errs = append(p.errs, errors.New(p.cur.Val))
+	p.errorf("%s", p.cur.Val)
 	return nil
 }
 
@@ -282,11 +462,21 @@ func (p *Parser) parseInteger() patukek_ast.Node {
 		p.errorf("unable to parse %q as integer", p.cur.Val)
 		return nil
 	}
-	return patukek_ast.NewInteger(i)
+	return patukek_ast.NewInteger(i, p.cur.Pos, p.cur.Pos+len(p.cur.Val))
+}
+
+func (p *Parser) parseFloat() patukek_ast.Node {
+	f, err := strconv.ParseFloat(p.cur.Val, 64)
+	if err != nil {
+		p.errorf("unable to parse %q as float", p.cur.Val)
+		return nil
+	}
+	return patukek_ast.NewFloat(f, p.cur.Pos, p.cur.Pos+len(p.cur.Val))
 }
 
 func (p *Parser) parseString() patukek_ast.Node {
-	s, err := patukek_ast.NewString(p.file, p.cur.Val, Parse, p.cur.Pos)
+	// The item starts after the opening quote.
+	s, err := patukek_ast.NewString(p.file, p.cur.Val, Parse, p.cur.Pos-1)
 	if err != nil {
 		p.errorf(err.Error())
 		return nil
@@ -294,6 +484,12 @@ func (p *Parser) parseString() patukek_ast.Node {
 	return s
 }
 
+func (p *Parser) parseNegative() patukek_ast.Node {
+	pos := p.cur.Pos
+	p.next()
+	return calc_ops.NewNegative(p.parseExpr(Multiplicative), pos)
+}
+
 func (p *Parser) parsePlus(left patukek_ast.Node) patukek_ast.Node {
 	pos := p.cur.Pos
 	prec := p.precedence()
@@ -395,6 +591,8 @@ func (p *Parser) parseAssign(left patukek_ast.Node) patukek_ast.Node {
 
 	if leftIsIdentifier && rightIsFunction {
 		fn.Name = i.String()
+		fn.NamePos = i.Pos()
+		right = fn
 	}
 
 	return patukek_ast.NewAssign(left, right, pos)
@@ -402,7 +600,15 @@ func (p *Parser) parseAssign(left patukek_ast.Node) patukek_ast.Node {
 
 func (p *Parser) parseCall(fn patukek_ast.Node) patukek_ast.Node {
 	pos := p.cur.Pos
-	return patukek_ast.NewCall(fn, p.parseNodeList(patukek_item.RParen), pos)
+	args := p.parseNodeList(patukek_item.RParen)
+	return patukek_ast.NewCall(fn, args, pos, p.cur.Pos+1)
+}
+
+func (p *Parser) parseDot(left patukek_ast.Node) patukek_ast.Node {
+	if !p.expectPeek(patukek_item.Ident) {
+		return nil
+	}
+	return patukek_ast.NewDot(left, p.cur.Val, p.cur.Pos+len(p.cur.Val))
 }
 
 func (p *Parser) parsePair() [2]patukek_ast.Node {
@@ -478,8 +684,23 @@ func (p *Parser) noParsePrefixFnError(t patukek_item.Type) {
 	p.errorf("no parse prefix function for %q found", t)
 }
 
+// ParseType parses a type written as in an annotation, such as the type
+// of a builtin.
+func ParseType(input string) (*patukek_ast.TypeExpr, error) {
+	p := newParser("<type>", input)
+
+	t := p.parseType()
+	if t != nil && !p.peek.Is(patukek_item.EOF) {
+		p.next()
+		p.errorf("unexpected %v after type", p.cur.Typ)
+	}
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return t, nil
+}
+
 func Parse(file, input string) (prog patukek_ast.Node, errs []error) {
-	items := patukek_lexer.Lex(input)
-	p := newParser(file, input, items)
+	p := newParser(file, input)
 	return p.parse(), p.errors()
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+package patukek_parser
+
+import (
+	"testing"
+
+	"patukek/internal/patukek_ast"
+)
+
+// TestAssignNamesFunction checks that a function assigned to a variable
+// knows its name, which it needs to call itself from a local scope.
+func TestAssignNamesFunction(t *testing.T) {
+	tree, errs := Parse("test.ptk", "fact = patukek(n) { n }")
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	var fn patukek_ast.Function
+	patukek_ast.Inspect(tree, func(n patukek_ast.Node) bool {
+		if f, ok := n.(patukek_ast.Function); ok {
+			fn = f
+		}
+		return true
+	})
+
+	if fn.Name != "fact" || fn.NamePos != 0 {
+		t.Errorf("function named %q at %d, want fact at 0", fn.Name, fn.NamePos)
+	}
+}
@@ -0,0 +1,35 @@
+// Package patukek_seed provides the seed corpus shared by the fuzz tests
+// of the compiler packages.
+package patukek_seed
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// AddExamples seeds the corpus of f with the programs of examples/.
+func AddExamples(f *testing.F) {
+	f.Helper()
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		f.Fatal("cannot locate the examples")
+	}
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "examples", "*.ptk"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	if len(files) == 0 {
+		f.Fatal("no examples found")
+	}
+	for _, path := range files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(b))
+	}
+}
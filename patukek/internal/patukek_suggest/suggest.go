@@ -0,0 +1,68 @@
+package patukek_suggest
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Distance returns the edit distance between a and b: the number of
+// rune insertions, deletions, substitutions and transpositions of
+// adjacent runes needed to turn one into the other.
+func Distance(a, b string) int {
+	var (
+		ra, rb = []rune(a), []rune(b)
+		prev2  = make([]int, len(rb)+1)
+		prev   = make([]int, len(rb)+1)
+		cur    = make([]int, len(rb)+1)
+	)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// Closest returns the candidate nearest to name, if any is close enough
+// to be a likely typo: within a third of the length of name, at least
+// one edit, and sharing some runes with it. A candidate differing only
+// in case is always close. Ties go to the earliest candidate.
+func Closest(name string, candidates []string) (string, bool) {
+	var (
+		best  string
+		limit = max(utf8.RuneCountInString(name)/3, 1)
+		dist  = limit + 1
+	)
+
+	for _, c := range candidates {
+		if c == name {
+			continue
+		}
+
+		d := Distance(name, c)
+		if strings.EqualFold(c, name) {
+			d = 0
+		} else if d >= max(utf8.RuneCountInString(name), utf8.RuneCountInString(c)) {
+			continue
+		}
+		if d < dist {
+			best, dist = c, d
+		}
+	}
+	return best, dist <= limit
+}
@@ -0,0 +1,63 @@
+package patukek_suggest
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"len", "len", 0},
+		{"lne", "len", 1},
+		{"printn", "println", 1},
+		{"prinltn", "println", 1},
+		{"kitten", "sitting", 3},
+		{"ёлка", "елка", 1},
+	}
+
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	names := []string{"println", "print", "len", "append", "Reverse", "x"}
+
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"printn", "println", true},
+		{"pritn", "print", true},
+		{"apend", "append", true},
+		{"reverse", "Reverse", true},
+		{"REVERSE", "Reverse", true},
+		{"lne", "len", true},
+		{"y", "", false},
+		{"foo", "", false},
+		{"lenght", "", false},
+		{"println", "print", true},
+	}
+
+	for _, tt := range tests {
+		got, ok := Closest(tt.name, names)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("Closest(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClosestTies(t *testing.T) {
+	if got, _ := Closest("cat", []string{"bat", "hat", "cat"}); got != "bat" {
+		t.Errorf("Closest picked %q, want the earliest candidate bat", got)
+	}
+	if _, ok := Closest("a", nil); ok {
+		t.Error("Closest found a candidate among none")
+	}
+}
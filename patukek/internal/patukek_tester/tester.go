@@ -0,0 +1,161 @@
+package patukek_tester
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_build"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_err"
+	"patukek/internal/patukek_vm"
+)
+
+// Result is the outcome of a single test function.
+type Result struct {
+	Name      string        `json:"name"`
+	File      string        `json:"file"`
+	Line      int           `json:"line"`
+	Passed    bool          `json:"passed"`
+	Error     string        `json:"error,omitempty"`
+	ErrorLine int           `json:"error_line,omitempty"`
+	Output    string        `json:"output,omitempty"`
+	Elapsed   time.Duration `json:"elapsed_ns"`
+}
+
+// Test is a top-level function whose name starts with "test_".
+type Test struct {
+	Name string
+	Line int
+}
+
+// Discover returns the test files among paths. Directories are searched
+// recursively for files ending in "_test.ptk".
+func Discover(paths ...string) ([]string, error) {
+	var files []string
+
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, "_test.ptk") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Tests returns the test functions defined at the top level of src.
+func Tests(tree patukek_ast.Node, src string) []Test {
+	var ret []Test
+
+	b, ok := tree.(*patukek_ast.Block)
+	if !ok {
+		return nil
+	}
+
+	for _, n := range b.Nodes {
+		a, ok := n.(patukek_ast.Assign)
+		if !ok {
+			continue
+		}
+		id, ok := a.Left().(patukek_ast.Identifier)
+		if !ok || !strings.HasPrefix(id.String(), "test_") {
+			continue
+		}
+		if _, ok := a.Right().(patukek_ast.Function); ok {
+			ret = append(ret, Test{Name: id.String(), Line: strings.Count(src[:id.Pos()], "\n") + 1})
+		}
+	}
+	return ret
+}
+
+// RunFile runs the tests of a file whose name matches filter, or all of
+// them if filter is nil. Every test runs in a fresh VM after the top
+// level of the file, so tests cannot see each other's side effects.
+func RunFile(ctx context.Context, path string, filter *regexp.Regexp) ([]Result, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src := string(b)
+
+	tree, errs := patukek_build.Check(path, src, nil)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	var ret []Result
+	for _, t := range Tests(tree, src) {
+		if filter != nil && !filter.MatchString(t.Name) {
+			continue
+		}
+		ret = append(ret, runTest(ctx, path, src, tree, t))
+	}
+	return ret, nil
+}
+
+func runTest(ctx context.Context, path, src string, tree patukek_ast.Node, t Test) Result {
+	var (
+		out   bytes.Buffer
+		state = patukek_vm.NewState()
+		start = time.Now()
+		res   = Result{Name: t.Name, File: path, Line: t.Line}
+	)
+
+	err := func() error {
+		c := patukek_compiler.NewWithState(state.Symbols, &state.Consts)
+		bc, err := patukek_build.Compile(c, path, src, tree)
+		if err != nil {
+			return err
+		}
+
+		vm := patukek_vm.NewWithState(path, bc, state, patukek_vm.Config{
+			Stdin:  strings.NewReader(""),
+			Stdout: &out,
+			Stderr: &out,
+		})
+		if err := vm.Run(ctx); err != nil {
+			return err
+		}
+
+		sym := state.Symbols.Store[t.Name]
+		_, err = vm.CallContext(ctx, state.Globals[sym.Index])
+		return err
+	}()
+
+	res.Elapsed = time.Since(start)
+	res.Output = out.String()
+	res.Passed = err == nil
+	if err != nil {
+		res.Error = err.Error()
+
+		var perr *patukek_err.Error
+		if errors.As(err, &perr) {
+			res.ErrorLine = perr.Line
+		}
+	}
+	return res
+}
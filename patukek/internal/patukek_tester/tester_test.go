@@ -0,0 +1,130 @@
+package patukek_tester
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const suite = `base = 40
+helper = patukek() { 2 }
+
+test_pass = patukek() {
+	assert_eq(1 + 1, 2)
+}
+
+test_fail = patukek() {
+	println("checking")
+	assert_eq(1 + 1, 3, "arithmetic")
+}
+
+test_globals = patukek() {
+	assert_eq(helper() + base, 42)
+}
+
+test_value = 5
+`
+
+func writeFile(t *testing.T, dir, name, src string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunFile(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "suite_test.ptk", suite)
+
+	res, err := RunFile(context.Background(), path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, r := range res {
+		names = append(names, r.Name)
+	}
+	if want := []string{"test_pass", "test_fail", "test_globals"}; !slices.Equal(names, want) {
+		t.Fatalf("ran %v, want %v", names, want)
+	}
+
+	for _, r := range res {
+		if want := r.Name != "test_fail"; r.Passed != want {
+			t.Errorf("%s passed = %v, want %v: %s", r.Name, r.Passed, want, r.Error)
+		}
+	}
+
+	fail := res[1]
+	if fail.Line != 8 || fail.ErrorLine != 10 {
+		t.Errorf("test_fail at line %d failed at line %d, want 8 and 10", fail.Line, fail.ErrorLine)
+	}
+	if !strings.Contains(fail.Error, "arithmetic") {
+		t.Errorf("test_fail error %q lacks the assertion message", fail.Error)
+	}
+	if fail.Output != "checking\n" {
+		t.Errorf("test_fail output = %q, want %q", fail.Output, "checking\n")
+	}
+}
+
+func TestRunFileFilter(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "suite_test.ptk", suite)
+
+	res, err := RunFile(context.Background(), path, regexp.MustCompile("^test_(pass|globals)$"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Name != "test_pass" || res[1].Name != "test_globals" {
+		t.Errorf("-run ^test_(pass|globals)$ ran %v", res)
+	}
+
+	res, err = RunFile(context.Background(), path, regexp.MustCompile("^nothing$"))
+	if err != nil || len(res) != 0 {
+		t.Errorf("-run ^nothing$ ran %v, %v", res, err)
+	}
+}
+
+func TestRunFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, src := range []string{"test_a = patukek() {", "test_a = patukek() { undefined_name }\nx: int = \"a\""} {
+		path := writeFile(t, dir, "bad_test.ptk", src)
+		if res, err := RunFile(context.Background(), path, nil); err == nil {
+			t.Errorf("RunFile(%q) = %v, want an error", src, res)
+		}
+	}
+
+	if _, err := RunFile(context.Background(), filepath.Join(dir, "missing_test.ptk"), nil); err == nil {
+		t.Error("RunFile of a missing file succeeded")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a_test.ptk", "")
+	b := writeFile(t, dir, "sub/b_test.ptk", "")
+	writeFile(t, dir, "main.ptk", "")
+	writeFile(t, dir, "sub/notes_test.txt", "")
+	plain := writeFile(t, dir, "explicit.ptk", "")
+
+	got, err := Discover(dir, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{a, b, plain}; !slices.Equal(got, want) {
+		t.Errorf("Discover = %v, want %v", got, want)
+	}
+
+	if _, err := Discover(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Discover of a missing path succeeded")
+	}
+}
@@ -0,0 +1,497 @@
+package patukek_types
+
+import (
+	"fmt"
+	"sort"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_ast/calc_ops"
+	"patukek/internal/patukek_err"
+	"patukek/internal/patukek_obj"
+)
+
+// Check infers the types of a program and reports the annotations that
+// do not hold, and the operations that fail at run time whatever the
+// values of unknown type. Values are of unknown type unless they come
+// from a literal, a builtin or annotated code, so that code without
+// annotations is only rejected for errors that are certain.
+func Check(file, src string, tree patukek_ast.Node) []error {
+	return CheckInfo(file, src, tree, nil)
+}
+
+// Info holds the types Check found for the variables of a program.
+type Info struct {
+	// Defs maps the offset of the first definition of every variable,
+	// assigned or a parameter, to the type of the values it holds.
+	Defs map[int]Type
+}
+
+// CheckInfo is like Check, and records the types of the variables in info
+// when it is not nil.
+func CheckInfo(file, src string, tree patukek_ast.Node, info *Info) []error {
+	return checkIn(file, src, tree, universe(patukek_obj.Builtins, nil), info)
+}
+
+// Env holds the names a program sees besides its own when it runs after
+// others on the same VM.
+type Env struct {
+	// Builtins are the builtins of the VM. Those without a signature,
+	// such as the ones registered by the host, hold values of any type.
+	Builtins []patukek_obj.BuiltinImpl
+	// Globals are the variables defined by the programs run before. They
+	// hold values of any type and hide the builtins of the same name.
+	Globals []string
+}
+
+// CheckEnv is like Check for a program that sees the names of env.
+func CheckEnv(file, src string, tree patukek_ast.Node, env Env) []error {
+	return checkIn(file, src, tree, universe(env.Builtins, env.Globals), nil)
+}
+
+// checkIn checks tree with scope as the outermost scope.
+func checkIn(file, src string, tree patukek_ast.Node, scope *scope, info *Info) []error {
+	c := &checker{file: file, src: src, scope: scope, info: info}
+	if info != nil && info.Defs == nil {
+		info.Defs = make(map[int]Type)
+	}
+
+	c.enter()
+	c.declare(tree)
+	c.typeOf(tree)
+
+	sort.SliceStable(c.errs, func(i, j int) bool {
+		return c.errs[i].Pos < c.errs[j].Pos
+	})
+
+	ret := make([]error, len(c.errs))
+	for i, e := range c.errs {
+		ret[i] = e
+	}
+	return ret
+}
+
+// variable is a name assigned in a scope.
+type variable struct {
+	typ      Type // of the last value assigned, nil before the first
+	declared Type // from an annotation, or nil
+	writes   int
+	pos      int // of the first definition, or -1 for builtins
+}
+
+type scope struct {
+	outer *scope
+	vars  map[string]*variable
+}
+
+// function is the function literal being checked.
+type function struct {
+	name    string
+	result  Type // declared, or nil
+	returns Type // of the values returned so far
+}
+
+func (f *function) String() string {
+	if f.name == "" {
+		return "function literal"
+	}
+	return f.name
+}
+
+type checker struct {
+	file  string
+	src   string
+	errs  []*patukek_err.Error
+	scope *scope
+	fn    *function
+	info  *Info
+}
+
+// universe returns the scope of the builtins and of the globals defined
+// before the program.
+func universe(builtins []patukek_obj.BuiltinImpl, globals []string) *scope {
+	s := &scope{vars: make(map[string]*variable)}
+	for _, b := range builtins {
+		t := Type(Any)
+		if b.Signature != "" {
+			t = signatureType(b.Signature)
+		}
+		s.vars[b.Name] = &variable{typ: t, writes: 1, pos: -1}
+	}
+	for _, g := range globals {
+		s.vars[g] = &variable{typ: Any, writes: 1, pos: -1}
+	}
+	return s
+}
+
+// spanner is a node or an annotation.
+type spanner interface {
+	Pos() int
+	End() int
+}
+
+func (c *checker) errorf(n spanner, s string, a ...any) {
+	err := patukek_err.NewSpan(c.file, c.src, n.Pos(), n.End(), s, a...)
+	c.errs = append(c.errs, err.(*patukek_err.Error))
+}
+
+func (c *checker) enter() {
+	c.scope = &scope{outer: c.scope, vars: make(map[string]*variable)}
+}
+
+func (c *checker) leave() {
+	c.scope = c.scope.outer
+}
+
+// declare records the variables assigned in body, outside of nested
+// functions, with their annotations.
+func (c *checker) declare(body patukek_ast.Node) {
+	patukek_ast.Inspect(body, func(n patukek_ast.Node) bool {
+		switch n := n.(type) {
+		case patukek_ast.Function:
+			return false
+		case patukek_ast.Assign:
+			if i, ok := n.Left().(patukek_ast.Identifier); ok {
+				v := c.variable(i.String())
+				if v.writes == 0 {
+					v.pos = i.Pos()
+				}
+				c.annotate(v, i)
+			}
+		}
+		return true
+	})
+}
+
+// variable returns the variable name of the current scope.
+func (c *checker) variable(name string) *variable {
+	v, ok := c.scope.vars[name]
+	if !ok {
+		v = &variable{pos: -1}
+		c.scope.vars[name] = v
+	}
+	return v
+}
+
+// record notes that v holds a value of type t.
+func (c *checker) record(v *variable, t Type) {
+	if c.info == nil || v.pos < 0 {
+		return
+	}
+	if v.declared != nil {
+		t = v.declared
+	}
+	c.info.Defs[v.pos] = join(c.info.Defs[v.pos], t)
+}
+
+// annotate counts a definition of v by i and records its annotation.
+func (c *checker) annotate(v *variable, i patukek_ast.Identifier) {
+	v.writes++
+	if i.Type == nil {
+		return
+	}
+
+	t := c.annotation(i.Type)
+	if v.declared != nil && v.declared.String() != t.String() {
+		c.errorf(i.Type, "%s is declared as %v, not %v", i, v.declared, t)
+		return
+	}
+	v.declared = t
+}
+
+func (c *checker) annotation(t *patukek_ast.TypeExpr) Type {
+	ret, err := FromAnnotation(t)
+	if err != nil {
+		c.errorf(t, "%v", err)
+		return Any
+	}
+	return ret
+}
+
+// lookup returns the type of the variable name. Variables assigned more
+// than once without an annotation may hold anything, depending on the
+// path taken to reach the lookup.
+func (c *checker) lookup(name string) Type {
+	for s := c.scope; s != nil; s = s.outer {
+		v, ok := s.vars[name]
+		switch {
+		case !ok:
+			continue
+		case v.declared != nil:
+			return v.declared
+		case v.typ == nil || v.writes > 1:
+			return Any
+		default:
+			return v.typ
+		}
+	}
+	return Any
+}
+
+// expr returns the type of the value of n.
+func (c *checker) expr(n patukek_ast.Node) Type {
+	if t := c.typeOf(n); t != nil {
+		return t
+	}
+	return Any
+}
+
+// typeOf returns the type of n, or nil if evaluating n never produces a
+// value because it returns from the function.
+func (c *checker) typeOf(n patukek_ast.Node) Type {
+	switch n := n.(type) {
+	case patukek_ast.Integer:
+		return Int
+
+	case patukek_ast.Float:
+		return Float
+
+	case patukek_ast.String:
+		for _, s := range n.Children() {
+			c.typeOf(s)
+		}
+		return String
+
+	case patukek_ast.Identifier:
+		return c.lookup(n.String())
+
+	case patukek_ast.List:
+		var elem Type
+		for _, e := range n.Elems() {
+			elem = join(elem, c.expr(e))
+		}
+		if elem == nil {
+			elem = Any
+		}
+		return List{elem}
+
+	case *patukek_ast.Block:
+		var ret Type = Null
+		for _, s := range n.Nodes {
+			ret = c.typeOf(s)
+		}
+		return ret
+
+	case patukek_ast.Assign:
+		return c.assign(n)
+
+	case patukek_ast.BinaryOp:
+		return c.binary(n)
+
+	case calc_ops.Negative:
+		t := c.expr(n.Operand())
+		switch {
+		case t == Any:
+			return Number
+		case !numeric(t):
+			c.errorf(n, "unsupported operator '-' for type %v", t)
+			return Any
+		}
+		return t
+
+	case patukek_ast.Call:
+		return c.call(n)
+
+	case patukek_ast.Dot:
+		if t := c.expr(n.Left()); t != Any && t != Object {
+			c.errorf(n, "type %v has no members", t)
+		}
+		return Any
+
+	case patukek_ast.Function:
+		return c.function(n)
+
+	case patukek_ast.IfExpr:
+		c.expr(n.Cond())
+		t := c.typeOf(n.Body())
+		if n.Else() == nil {
+			return join(t, Null)
+		}
+		return join(t, c.typeOf(n.Else()))
+
+	case patukek_ast.Return:
+		c.ret(n)
+		return nil
+
+	default:
+		return Any
+	}
+}
+
+func (c *checker) assign(n patukek_ast.Assign) Type {
+	t := c.expr(n.Right())
+
+	switch l := n.Left().(type) {
+	case patukek_ast.Identifier:
+		v := c.variable(l.String())
+		if v.declared != nil && !Consistent(t, v.declared) {
+			c.errorf(n.Right(), "cannot use %v as %v in assignment to %s", t, v.declared, l)
+		}
+		v.typ = t
+		c.record(v, t)
+
+	case patukek_ast.Dot:
+		c.expr(l)
+	}
+	return t
+}
+
+func (c *checker) binary(n patukek_ast.BinaryOp) Type {
+	var (
+		l, r   = n.Operands()
+		lt, rt = c.expr(l), c.expr(r)
+	)
+
+	switch n.Op() {
+	case "==", "!=", "&&", "||":
+		return Bool
+
+	case "<", ">", "<=", ">=":
+		if lt == String && numeric(rt) || numeric(lt) && rt == String {
+			c.errorf(n, "unsupported operator '%s' for types %v and %v", n.Op(), lt, rt)
+		}
+		return Bool
+
+	default:
+		return c.arith(n, lt, rt)
+	}
+}
+
+// arith returns the type of an arithmetic operation. Only "+" applies to
+// strings as well as numbers.
+func (c *checker) arith(n patukek_ast.BinaryOp, l, r Type) Type {
+	op := n.Op()
+	operand := func(t Type) bool {
+		return t == Any || numeric(t) || op == "+" && t == String
+	}
+
+	if !operand(l) || !operand(r) || l == String && numeric(r) || numeric(l) && r == String {
+		c.errorf(n, "unsupported operator '%s' for types %v and %v", op, l, r)
+		return Any
+	}
+
+	switch {
+	case l == String || r == String:
+		return String
+	case l == Any && r == Any && op == "+":
+		return Any
+	case l == Int && r == Int:
+		return Int
+	case l == Float || r == Float:
+		return Float
+	default:
+		return Number
+	}
+}
+
+func (c *checker) call(n patukek_ast.Call) Type {
+	ft := c.expr(n.Fn)
+
+	args := make([]Type, len(n.Args))
+	for i, a := range n.Args {
+		args[i] = c.expr(a)
+	}
+
+	f, ok := ft.(Func)
+	if !ok {
+		if ft != Any {
+			c.errorf(n.Fn, "cannot call %v", ft)
+		}
+		return Any
+	}
+
+	name := "function"
+	if i, ok := n.Fn.(patukek_ast.Identifier); ok {
+		name = i.String()
+	}
+
+	if !f.accepts(len(args)) {
+		c.errorf(n, "%s", arityError(name, f, len(args)))
+		return f.Result
+	}
+
+	for i, a := range args {
+		if p := f.param(i); !Consistent(a, p) {
+			c.errorf(n.Args[i], "cannot use %v as %v in argument %d to %s", a, p, i+1, name)
+		}
+	}
+	return f.Result
+}
+
+// arityError describes a call of the function name of type f with n
+// arguments it does not accept.
+func arityError(name string, f Func, n int) string {
+	unit := "arguments"
+	if f.arity() == "1" {
+		unit = "argument"
+	}
+	return fmt.Sprintf("%s takes %s %s, got %d", name, f.arity(), unit, n)
+}
+
+func (c *checker) function(n patukek_ast.Function) Type {
+	f := Func{Min: len(n.Params()), Result: Any}
+	fn := &function{name: n.Name}
+	if n.Result != nil {
+		fn.result = c.annotation(n.Result)
+		f.Result = fn.result
+	}
+
+	outer := c.fn
+	c.fn = fn
+	c.enter()
+	defer func() {
+		c.leave()
+		c.fn = outer
+	}()
+
+	self := &variable{writes: 1, pos: -1}
+	if n.Name != "" {
+		c.scope.vars[n.Name] = self
+	}
+	for _, p := range n.Params() {
+		v := &variable{typ: Any, pos: p.Pos()}
+		c.scope.vars[p.String()] = v
+		c.annotate(v, p)
+		c.record(v, Any)
+		f.Params = append(f.Params, c.lookup(p.String()))
+	}
+	self.typ = f
+
+	c.declare(n.Body())
+	tail := c.typeOf(n.Body())
+
+	if fn.result != nil {
+		if tail != nil && !Consistent(tail, fn.result) {
+			c.errorf(last(n.Body()), "cannot use %v as %v in return from %v", tail, fn.result, fn)
+		}
+		return f
+	}
+
+	if f.Result = join(fn.returns, tail); f.Result == nil {
+		f.Result = Null
+	}
+	return f
+}
+
+func (c *checker) ret(n patukek_ast.Return) {
+	var t Type = Null
+	if n.Value() != nil {
+		t = c.expr(n.Value())
+	}
+
+	if c.fn == nil {
+		return
+	}
+	if c.fn.result != nil && !Consistent(t, c.fn.result) {
+		c.errorf(n, "cannot use %v as %v in return from %v", t, c.fn.result, c.fn)
+	}
+	c.fn.returns = join(c.fn.returns, t)
+}
+
+// last returns the last statement of a block, or the block if it is
+// empty.
+func last(n patukek_ast.Node) patukek_ast.Node {
+	if b, ok := n.(*patukek_ast.Block); ok && len(b.Nodes) > 0 {
+		return b.Nodes[len(b.Nodes)-1]
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package patukek_types
+
+import (
+	"strings"
+	"testing"
+
+	"patukek/internal/patukek_parser"
+)
+
+func check(t *testing.T, src string) []error {
+	t.Helper()
+
+	tree, errs := patukek_parser.Parse("test.ptk", src)
+	if len(errs) > 0 {
+		t.Fatalf("parse %q: %v", src, errs)
+	}
+	return Check("test.ptk", src, tree)
+}
+
+// TestCheckValid holds programs that run, which Check must accept
+// whatever the declared types of the builtins they call.
+func TestCheckValid(t *testing.T) {
+	tests := []string{
+		`append(vector(1, 2, 3), 4)`,
+		`push(clist(2, 3), 1)`,
+		`append([1], "a", 2.5)`,
+		"v = vector(1)\nlen(append(v, 2))",
+		"l = readline()\nl",
+		"n = readline()\nif n == 0 { println(\"eof\") }",
+		`map([1, 2], patukek(x) { x * 2 })`,
+		`filter([1, 2], patukek(x) { x % 2 })`,
+		`min(1, 2.5) + max(3, 4)`,
+	}
+
+	for _, src := range tests {
+		if errs := check(t, src); len(errs) > 0 {
+			t.Errorf("Check(%q) = %v, want no error", src, errs)
+		}
+	}
+}
+
+func TestCheckInvalid(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"f = patukek(x: int) { x }\nf(\"a\")", "cannot use string as int in argument 1 to f"},
+		{`len(1, 2)`, "len takes 1 argument, got 2"},
+		{`x: string = 1`, "cannot use int as string in assignment to x"},
+		{`"a" - 1`, "unsupported operator '-' for types string and int"},
+	}
+
+	for _, tt := range tests {
+		errs := check(t, tt.src)
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("Check(%q) = %v, want %q", tt.src, errs, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package patukek_types
+
+import (
+	"testing"
+
+	"patukek/internal/patukek_parser"
+	"patukek/internal/patukek_seed"
+)
+
+func FuzzCheck(f *testing.F) {
+	patukek_seed.AddExamples(f)
+	f.Add("add = patukek(a: int, b: int) -> int { a + b }\nadd(\"s\", [1.5])")
+	f.Add(`apply = patukek(f: patukek(int) -> list[int], x: int) { f(x) }`)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		tree, errs := patukek_parser.Parse("fuzz.ptk", in)
+		if len(errs) == 0 {
+			_ = Check("fuzz.ptk", in, tree)
+		}
+	})
+}
+
+func FuzzInfer(f *testing.F) {
+	patukek_seed.AddExamples(f)
+	f.Add("comp = patukek(a, b, f) { f(a, b) }\ncomp(1, \"s\", patukek(x, y) { x + y })")
+	f.Add("g = patukek(h) { h(h) }\nl = [1, 2.0]\n-\"x\"")
+
+	f.Fuzz(func(t *testing.T, in string) {
+		tree, errs := patukek_parser.Parse("fuzz.ptk", in)
+		if len(errs) == 0 {
+			_, _ = Infer("fuzz.ptk", in, tree)
+		}
+	})
+}
@@ -0,0 +1,698 @@
+package patukek_types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_ast/calc_ops"
+	"patukek/internal/patukek_err"
+	"patukek/internal/patukek_suggest"
+)
+
+// Signature is the inferred type of a variable assigned at the top level
+// of a program.
+type Signature struct {
+	Name string
+	Pos  int
+	Type Type
+}
+
+func (s Signature) String() string {
+	var p printer
+	t := p.print(s.Type)
+	return s.Name + ": " + t + p.where()
+}
+
+// Infer infers the principal type of every expression of a program with
+// Hindley-Milner inference, and returns the types of its top-level
+// variables in the order they are first assigned. Unlike Check, it
+// rejects every program whose values cannot be given a single type: a
+// variable keeps the type of its first assignment, list elements share
+// one type and so do the operands of an operator. Function literals are
+// polymorphic in the types their body leaves open.
+func Infer(file, src string, tree patukek_ast.Node) ([]Signature, []error) {
+	in := &inferer{file: file, src: src, subst: make(map[int]Type), env: builtinEnv()}
+
+	in.enter()
+	top := in.env
+	in.typeOf(tree)
+
+	sigs := make([]Signature, len(top.order))
+	for i, name := range top.order {
+		b := top.vars[name]
+		sigs[i] = Signature{Name: name, Pos: b.pos, Type: in.apply(b.scheme.Type)}
+	}
+
+	sort.SliceStable(in.errs, func(i, j int) bool {
+		return in.errs[i].Pos < in.errs[j].Pos
+	})
+
+	errs := make([]error, len(in.errs))
+	for i, e := range in.errs {
+		errs[i] = e
+	}
+	return sigs, errs
+}
+
+// Scheme is a type generalized over the variables Vars, which stand for
+// a new type of their class at every use of the scheme.
+type Scheme struct {
+	Vars []Var
+	Type Type
+}
+
+type binding struct {
+	scheme Scheme
+	pos    int
+}
+
+type env struct {
+	outer *env
+	vars  map[string]*binding
+	order []string
+}
+
+func (e *env) lookup(name string) *binding {
+	for ; e != nil; e = e.outer {
+		if b, ok := e.vars[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// builtinEnv returns the environment of the builtins, which are
+// generalized over all their type variables.
+func builtinEnv() *env {
+	e := &env{vars: make(map[string]*binding, len(builtinTypes))}
+	for name, t := range builtinTypes {
+		e.vars[name] = &binding{scheme: Scheme{Vars: freeVars(t, nil), Type: t}, pos: -1}
+	}
+	return e
+}
+
+type inferer struct {
+	file  string
+	src   string
+	errs  []*patukek_err.Error
+	subst map[int]Type
+	vars  int
+	env   *env
+	fn    *function
+}
+
+func (in *inferer) errorf(n spanner, s string, a ...any) {
+	var p printer
+	for i, x := range a {
+		switch x.(type) {
+		case Var, Basic, List, Func:
+			a[i] = p.print(in.apply(x.(Type)))
+		}
+	}
+
+	msg := fmt.Sprintf(s, a...) + p.where()
+	err := patukek_err.NewSpan(in.file, in.src, n.Pos(), n.End(), "%s", msg)
+	in.errs = append(in.errs, err.(*patukek_err.Error))
+}
+
+// mismatch reports the failure err to unify two types, described by s.
+func (in *inferer) mismatch(n spanner, err error, s string, a ...any) {
+	var occurs occursError
+	if errors.As(err, &occurs) {
+		s += ": %v would contain itself"
+		a = append(a, occurs.v)
+	}
+	in.errorf(n, s, a...)
+}
+
+func (in *inferer) enter() {
+	in.env = &env{outer: in.env, vars: make(map[string]*binding)}
+}
+
+func (in *inferer) leave() {
+	in.env = in.env.outer
+}
+
+func (in *inferer) newVar(c Class) Var {
+	in.vars++
+	return Var{ID: in.vars, Class: c}
+}
+
+// apply returns t with its bound variables replaced by their types.
+func (in *inferer) apply(t Type) Type {
+	return mapType(t, func(t Type) Type {
+		if v, ok := t.(Var); ok {
+			if b, ok := in.subst[v.ID]; ok {
+				return in.apply(b)
+			}
+		}
+		return t
+	})
+}
+
+// resolve returns t, or the type t is bound to if it is a variable.
+func (in *inferer) resolve(t Type) Type {
+	for {
+		v, ok := t.(Var)
+		if !ok {
+			return t
+		}
+		b, ok := in.subst[v.ID]
+		if !ok {
+			return t
+		}
+		t = b
+	}
+}
+
+var errMismatch = errors.New("mismatched types")
+
+type occursError struct {
+	v Var
+}
+
+func (e occursError) Error() string {
+	return fmt.Sprintf("%v occurs in its own type", e.v)
+}
+
+// unify binds the variables of a and b so that they are the same type.
+// Any is the same type as every other, and number as int and float.
+func (in *inferer) unify(a, b Type) error {
+	a, b = in.resolve(a), in.resolve(b)
+	if a == Any || b == Any {
+		return nil
+	}
+
+	if v, ok := a.(Var); ok {
+		return in.bind(v, b)
+	}
+	if v, ok := b.(Var); ok {
+		return in.bind(v, a)
+	}
+
+	switch a := a.(type) {
+	case Basic:
+		if b, ok := b.(Basic); ok && (a == b || a == Number && numeric(b) || b == Number && numeric(a)) {
+			return nil
+		}
+
+	case List:
+		if b, ok := b.(List); ok {
+			return in.unify(a.Elem, b.Elem)
+		}
+
+	case Func:
+		if b, ok := b.(Func); ok {
+			return in.unifyFunc(a, b)
+		}
+	}
+	return errMismatch
+}
+
+// unifyFunc unifies the functions a and b, which need only agree on the
+// arguments of the calls both accept when one takes optional or variadic
+// arguments.
+func (in *inferer) unifyFunc(a, b Func) error {
+	var n int
+	switch {
+	case a.fixed() && b.fixed():
+		if len(a.Params) != len(b.Params) {
+			return errMismatch
+		}
+		n = len(a.Params)
+	case a.fixed() || b.fixed():
+		if !b.fixed() {
+			a, b = b, a
+		}
+		if !a.accepts(len(b.Params)) {
+			return errMismatch
+		}
+		n = len(b.Params)
+	default:
+		n = min(len(a.Params), len(b.Params))
+	}
+
+	for i := range n {
+		if err := in.unify(a.param(i), b.param(i)); err != nil {
+			return err
+		}
+	}
+	return in.unify(a.Result, b.Result)
+}
+
+// bind binds v to t, which is resolved.
+func (in *inferer) bind(v Var, t Type) error {
+	if w, ok := t.(Var); ok {
+		switch {
+		case v.ID == w.ID:
+		case v.Class > w.Class:
+			in.subst[w.ID] = v
+		default:
+			in.subst[v.ID] = w
+		}
+		return nil
+	}
+
+	if !v.Class.admits(t) {
+		return errMismatch
+	}
+	for _, w := range freeVars(in.apply(t), nil) {
+		if w.ID == v.ID {
+			return occursError{v}
+		}
+	}
+	in.subst[v.ID] = t
+	return nil
+}
+
+// freeVars appends the variables of t to vars, once each.
+func freeVars(t Type, vars []Var) []Var {
+	mapType(t, func(t Type) Type {
+		if v, ok := t.(Var); ok && !containsVar(vars, v) {
+			vars = append(vars, v)
+		}
+		return t
+	})
+	return vars
+}
+
+func containsVar(vars []Var, v Var) bool {
+	for _, w := range vars {
+		if w.ID == v.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// instantiate returns the type of s with its variables replaced by new
+// ones, and every number by a new variable of that class.
+func (in *inferer) instantiate(s Scheme) Type {
+	vars := make(map[int]Var, len(s.Vars))
+	for _, v := range s.Vars {
+		vars[v.ID] = in.newVar(v.Class)
+	}
+
+	return mapType(in.apply(s.Type), func(t Type) Type {
+		switch t := t.(type) {
+		case Var:
+			if v, ok := vars[t.ID]; ok {
+				return v
+			}
+		case Basic:
+			if t == Number {
+				return in.newVar(Numeric)
+			}
+		}
+		return t
+	})
+}
+
+// generalize returns the scheme of t over the variables that no
+// variable in scope depends on.
+func (in *inferer) generalize(t Type) Scheme {
+	var bound []Var
+	for e := in.env; e.outer != nil; e = e.outer {
+		for _, b := range e.vars {
+			for _, v := range freeVars(in.apply(b.scheme.Type), nil) {
+				if !containsVar(b.scheme.Vars, v) {
+					bound = append(bound, v)
+				}
+			}
+		}
+	}
+
+	s := Scheme{Type: in.apply(t)}
+	for _, v := range freeVars(s.Type, nil) {
+		if !containsVar(bound, v) {
+			s.Vars = append(s.Vars, v)
+		}
+	}
+	return s
+}
+
+// annotation returns the type written in t.
+func (in *inferer) annotation(t *patukek_ast.TypeExpr) Type {
+	ret, err := FromAnnotation(t)
+	if err != nil {
+		in.errorf(t, "%v", err)
+		return in.newVar(Unrestricted)
+	}
+	return in.instantiate(Scheme{Type: ret})
+}
+
+// expr returns the type of the value of n.
+func (in *inferer) expr(n patukek_ast.Node) Type {
+	if t := in.typeOf(n); t != nil {
+		return t
+	}
+	return in.newVar(Unrestricted)
+}
+
+// typeOf returns the type of n, or nil if evaluating n never produces a
+// value because it returns from the function.
+func (in *inferer) typeOf(n patukek_ast.Node) Type {
+	switch n := n.(type) {
+	case patukek_ast.Integer:
+		return Int
+
+	case patukek_ast.Float:
+		return Float
+
+	case patukek_ast.String:
+		for _, s := range n.Children() {
+			in.typeOf(s)
+		}
+		return String
+
+	case patukek_ast.Identifier:
+		return in.lookup(n)
+
+	case patukek_ast.List:
+		elem := in.newVar(Unrestricted)
+		for i, e := range n.Elems() {
+			t := in.expr(e)
+			if err := in.unify(t, elem); err != nil {
+				in.mismatch(e, err, "cannot use %v as %v in list element %d", t, elem, i+1)
+			}
+		}
+		return List{elem}
+
+	case *patukek_ast.Block:
+		var ret Type = Null
+		for _, s := range n.Nodes {
+			ret = in.typeOf(s)
+		}
+		return ret
+
+	case patukek_ast.Assign:
+		return in.assign(n)
+
+	case patukek_ast.BinaryOp:
+		return in.binary(n)
+
+	case calc_ops.Negative:
+		t := in.expr(n.Operand())
+		v := in.newVar(Numeric)
+		if err := in.unify(t, v); err != nil {
+			in.mismatch(n, err, "unsupported operator '-' for type %v", t)
+		}
+		return v
+
+	case patukek_ast.Call:
+		return in.call(n)
+
+	case patukek_ast.Dot:
+		in.expr(n.Left())
+		return Any
+
+	case patukek_ast.Function:
+		return in.function(n)
+
+	case patukek_ast.IfExpr:
+		return in.ifExpr(n)
+
+	case patukek_ast.Return:
+		in.ret(n)
+		return nil
+
+	default:
+		return Any
+	}
+}
+
+func (in *inferer) lookup(n patukek_ast.Identifier) Type {
+	name := n.String()
+	if b := in.env.lookup(name); b != nil {
+		return in.instantiate(b.scheme)
+	}
+
+	var names []string
+	for e := in.env; e != nil; e = e.outer {
+		scope := make([]string, 0, len(e.vars))
+		for name := range e.vars {
+			scope = append(scope, name)
+		}
+		sort.Strings(scope)
+		names = append(names, scope...)
+	}
+
+	if s, ok := patukek_suggest.Closest(name, names); ok {
+		in.errorf(n, "undefined variable %s, did you mean %s?", name, s)
+	} else {
+		in.errorf(n, "undefined variable %s", name)
+	}
+	return in.newVar(Unrestricted)
+}
+
+func (in *inferer) assign(n patukek_ast.Assign) Type {
+	l, ok := n.Left().(patukek_ast.Identifier)
+	if !ok {
+		in.expr(n.Left())
+		return in.expr(n.Right())
+	}
+
+	name := l.String()
+	t := in.expr(n.Right())
+	if l.Type != nil {
+		decl := in.annotation(l.Type)
+		if err := in.unify(t, decl); err != nil {
+			in.mismatch(n.Right(), err, "cannot use %v as %v in assignment to %s", t, decl, name)
+		}
+		t = decl
+	}
+
+	// A variable keeps the type of its first assignment in a scope.
+	if b, ok := in.env.vars[name]; ok {
+		prev := in.instantiate(b.scheme)
+		if err := in.unify(t, prev); err != nil {
+			in.mismatch(n.Right(), err, "cannot use %v as %v in assignment to %s", t, prev, name)
+		}
+		return t
+	}
+
+	s := Scheme{Type: t}
+	if _, ok := n.Right().(patukek_ast.Function); ok {
+		s = in.generalize(t)
+	}
+	in.env.vars[name] = &binding{scheme: s, pos: l.Pos()}
+	in.env.order = append(in.env.order, name)
+	return t
+}
+
+func (in *inferer) binary(n patukek_ast.BinaryOp) Type {
+	var (
+		l, r   = n.Operands()
+		lt, rt = in.expr(l), in.expr(r)
+	)
+
+	switch n.Op() {
+	case "&&", "||":
+		return Bool
+
+	case "==", "!=":
+		if err := in.unify(lt, rt); err != nil {
+			in.mismatch(n, err, "mismatched types %v and %v for operator '%s'", lt, rt, n.Op())
+		}
+		return Bool
+
+	case "<", ">", "<=", ">=":
+		in.operands(n, lt, rt, Ordered)
+		return Bool
+
+	case "+":
+		return in.operands(n, lt, rt, Ordered)
+
+	default:
+		return in.operands(n, lt, rt, Numeric)
+	}
+}
+
+// operands unifies the operands of n, of types l and r, with a variable
+// of class c, and returns it.
+func (in *inferer) operands(n patukek_ast.BinaryOp, l, r Type, c Class) Type {
+	v := in.newVar(c)
+
+	err := in.unify(l, v)
+	if err == nil {
+		err = in.unify(r, v)
+	}
+	if err != nil {
+		in.mismatch(n, err, "unsupported operator '%s' for types %v and %v", n.Op(), l, r)
+	}
+	return v
+}
+
+func (in *inferer) call(n patukek_ast.Call) Type {
+	ft := in.apply(in.expr(n.Fn))
+
+	args := make([]Type, len(n.Args))
+	for i, a := range n.Args {
+		args[i] = in.expr(a)
+	}
+
+	name := "function"
+	if i, ok := n.Fn.(patukek_ast.Identifier); ok {
+		name = i.String()
+	}
+
+	switch f := ft.(type) {
+	case Func:
+		if !f.accepts(len(args)) {
+			in.errorf(n, "%s", arityError(name, f, len(args)))
+			return f.Result
+		}
+
+		for i, a := range args {
+			p := f.param(i)
+			if err := in.unify(a, p); err != nil {
+				in.mismatch(n.Args[i], err, "cannot use %v as %v in argument %d to %s", a, p, i+1, name)
+			}
+		}
+		return f.Result
+
+	case Var:
+		want := Func{Params: args, Min: len(args), Result: in.newVar(Unrestricted)}
+		if err := in.unify(f, want); err != nil {
+			in.mismatch(n.Fn, err, "cannot call %v", f)
+		}
+		return want.Result
+
+	default:
+		if ft != Any {
+			in.errorf(n.Fn, "cannot call %v", ft)
+		}
+		return Any
+	}
+}
+
+func (in *inferer) function(n patukek_ast.Function) Type {
+	f := Func{Min: len(n.Params()), Result: in.newVar(Unrestricted)}
+	for _, p := range n.Params() {
+		var t Type = in.newVar(Unrestricted)
+		if p.Type != nil {
+			t = in.annotation(p.Type)
+		}
+		f.Params = append(f.Params, t)
+	}
+	if n.Result != nil {
+		in.unify(f.Result, in.annotation(n.Result))
+	}
+
+	outer := in.fn
+	in.fn = &function{name: n.Name, result: f.Result}
+	in.enter()
+	defer func() {
+		in.leave()
+		in.fn = outer
+	}()
+
+	// The function is not polymorphic in its own body.
+	if n.Name != "" {
+		in.env.vars[n.Name] = &binding{scheme: Scheme{Type: f}, pos: n.Pos()}
+	}
+	for i, p := range n.Params() {
+		in.env.vars[p.String()] = &binding{scheme: Scheme{Type: f.Params[i]}, pos: p.Pos()}
+	}
+
+	if tail := in.typeOf(n.Body()); tail != nil {
+		if err := in.unify(tail, f.Result); err != nil {
+			in.mismatch(last(n.Body()), err, "cannot use %v as %v in return from %v", tail, f.Result, in.fn)
+		}
+	}
+	return f
+}
+
+func (in *inferer) ret(n patukek_ast.Return) {
+	var t Type = Null
+	if n.Value() != nil {
+		t = in.expr(n.Value())
+	}
+
+	if in.fn == nil {
+		return
+	}
+	if err := in.unify(t, in.fn.result); err != nil {
+		in.mismatch(n, err, "cannot use %v as %v in return from %v", t, in.fn.result, in.fn)
+	}
+}
+
+// ifExpr returns the type of an if expression. Conditions may be of any
+// type, as values other than false and null count as true, and an if
+// without an else is null whatever its body.
+func (in *inferer) ifExpr(n patukek_ast.IfExpr) Type {
+	in.expr(n.Cond())
+	t := in.typeOf(n.Body())
+	if n.Else() == nil {
+		return Null
+	}
+
+	e := in.typeOf(n.Else())
+	switch {
+	case t == nil:
+		return e
+	case e == nil:
+		return t
+	}
+
+	if err := in.unify(e, t); err != nil {
+		in.mismatch(last(n.Else()), err, "cannot use %v as %v in else branch", e, t)
+	}
+	return t
+}
+
+// printer formats types, naming their variables a, b, c... in the order
+// they appear.
+type printer struct {
+	vars []Var
+}
+
+func (p *printer) print(t Type) string {
+	switch t := t.(type) {
+	case Var:
+		i := 0
+		for i < len(p.vars) && p.vars[i].ID != t.ID {
+			i++
+		}
+		if i == len(p.vars) {
+			p.vars = append(p.vars, t)
+		}
+		return varName(i)
+
+	case List:
+		return formatList(t, p.print)
+
+	case Func:
+		return formatFunc(t, p.print)
+
+	default:
+		return t.String()
+	}
+}
+
+// where returns the classes of the restricted variables printed so far.
+func (p *printer) where() string {
+	var classes []string
+	for i, v := range p.vars {
+		if v.Class != Unrestricted {
+			classes = append(classes, varName(i)+": "+v.Class.String())
+		}
+	}
+
+	if len(classes) == 0 {
+		return ""
+	}
+	return " where " + strings.Join(classes, ", ")
+}
+
+func varName(i int) string {
+	name := string(rune('a' + i%26))
+	if i >= 26 {
+		name += strconv.Itoa(i / 26)
+	}
+	return name
+}
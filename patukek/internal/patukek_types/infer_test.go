@@ -0,0 +1,62 @@
+package patukek_types
+
+import (
+	"strings"
+	"testing"
+
+	"patukek/internal/patukek_parser"
+)
+
+func infer(t *testing.T, src string) ([]Signature, []error) {
+	t.Helper()
+
+	tree, errs := patukek_parser.Parse("test.ptk", src)
+	if len(errs) > 0 {
+		t.Fatalf("parse %q: %v", src, errs)
+	}
+	return Infer("test.ptk", src, tree)
+}
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"id = patukek(x) { x }", "id: patukek(a) -> a"},
+		{"comp = patukek(a, b, f) { f(a, b) }", "comp: patukek(a, b, patukek(a, b) -> c) -> c"},
+		{"min = patukek(a, b) {\n\tif a < b {\n\t\treturn a\n\t}\n\tb\n}", "min: patukek(a, a) -> a where a: ordered"},
+		{"add = patukek(a: number, b) { a + b }", "add: patukek(a, a) -> a where a: number"},
+		{"fib = patukek(n) {\n\tif n < 2 {\n\t\treturn n\n\t}\n\tfib(n-1) + fib(n-2)\n}", "fib: patukek(int) -> int"},
+		{"m = map([1, 2], patukek(v) { v * 2 })", "m: list[int]"},
+		{"o = patukek(xs) { sort(xs) }", "o: patukek(list[a]) -> list[a]"},
+		{"l = readline()", "l: any"},
+	}
+
+	for _, tt := range tests {
+		sigs, errs := infer(t, tt.src)
+		if len(errs) > 0 || len(sigs) != 1 || sigs[0].String() != tt.want {
+			t.Errorf("Infer(%q) = %v, %v, want %s", tt.src, sigs, errs, tt.want)
+		}
+	}
+}
+
+func TestInferErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"x = 1\nx = \"two\"", "cannot use string as int in assignment to x"},
+		{"l = [1, \"s\"]", "cannot use string as int in list element 2"},
+		{"r = 1 + 2.0", "unsupported operator '+' for types int and float"},
+		{"g = patukek(h) { h(h) }", "would contain itself"},
+		{"z = upper(3)", "cannot use int as string in argument 1 to upper"},
+		{"t = yeer", "undefined variable yeer"},
+	}
+
+	for _, tt := range tests {
+		_, errs := infer(t, tt.src)
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("Infer(%q) = %v, want %q", tt.src, errs, tt.want)
+		}
+	}
+}
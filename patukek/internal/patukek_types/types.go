@@ -0,0 +1,425 @@
+package patukek_types
+
+import (
+	"fmt"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_obj"
+	"patukek/internal/patukek_parser"
+)
+
+// Type is a static type. Any stands for every value whose type is not
+// known, which is what unannotated code mostly deals with.
+type Type interface {
+	String() string
+}
+
+// Basic is a type without parameters.
+type Basic string
+
+const (
+	Any    Basic = "any"
+	Int    Basic = "int"
+	Float  Basic = "float"
+	Number Basic = "number"
+	Bool   Basic = "bool"
+	String Basic = "string"
+	Null   Basic = "null"
+	Error  Basic = "error"
+	Vector Basic = "vector"
+	Cons   Basic = "cons"
+	Object Basic = "object"
+)
+
+func (b Basic) String() string {
+	return string(b)
+}
+
+// List is the type of lists whose elements are all of type Elem.
+type List struct {
+	Elem Type
+}
+
+func (l List) String() string {
+	return formatList(l, Type.String)
+}
+
+func formatList(l List, str func(Type) string) string {
+	if l.Elem == Any {
+		return "list"
+	}
+	return "list[" + str(l.Elem) + "]"
+}
+
+// Func is the type of functions. The first Min parameters are required;
+// a variadic function accepts any number of arguments of the type of its
+// last parameter.
+type Func struct {
+	Params   []Type
+	Min      int
+	Variadic bool
+	Result   Type
+}
+
+func (f Func) String() string {
+	return formatFunc(f, Type.String)
+}
+
+// formatFunc formats f, using str for the types of its parameters and
+// result.
+func formatFunc(f Func, str func(Type) string) string {
+	var params []string
+
+	for i, p := range f.Params {
+		s := str(p)
+		switch {
+		case f.Variadic && i == len(f.Params)-1:
+			s += "..."
+		case i >= f.Min:
+			s = "[" + s + "]"
+		}
+		params = append(params, s)
+	}
+	return fmt.Sprintf("patukek(%s) -> %s", strings.Join(params, ", "), str(f.Result))
+}
+
+// param returns the type of the i-th argument of a call.
+func (f Func) param(i int) Type {
+	switch {
+	case i < len(f.Params):
+		return f.Params[i]
+	case f.Variadic && len(f.Params) > 0:
+		return f.Params[len(f.Params)-1]
+	default:
+		return Any
+	}
+}
+
+// arity describes the number of arguments f takes.
+func (f Func) arity() string {
+	switch {
+	case f.Variadic:
+		return fmt.Sprintf("at least %d", f.Min)
+	case f.Min == len(f.Params):
+		return fmt.Sprint(f.Min)
+	default:
+		return fmt.Sprintf("%d to %d", f.Min, len(f.Params))
+	}
+}
+
+func (f Func) accepts(n int) bool {
+	return n >= f.Min && (f.Variadic || n <= len(f.Params))
+}
+
+// fixed reports whether f takes a fixed number of arguments.
+func (f Func) fixed() bool {
+	return !f.Variadic && f.Min == len(f.Params)
+}
+
+// Class restricts the types a type variable may stand for.
+type Class int
+
+const (
+	Unrestricted Class = iota
+	Ordered            // int, float and string, the operands of "+" and "<"
+	Numeric            // int and float
+)
+
+func (c Class) String() string {
+	switch c {
+	case Ordered:
+		return "ordered"
+	case Numeric:
+		return "number"
+	default:
+		return "any"
+	}
+}
+
+func (c Class) admits(t Type) bool {
+	switch c {
+	case Ordered:
+		return numeric(t) || t == String
+	case Numeric:
+		return numeric(t)
+	default:
+		return true
+	}
+}
+
+// Var is a type variable, which stands for any type of its class. Only
+// inference deals with type variables.
+type Var struct {
+	ID    int
+	Class Class
+}
+
+func (v Var) String() string {
+	return fmt.Sprintf("t%d", v.ID)
+}
+
+// mapType returns t with every type without parameters replaced by f of
+// it.
+func mapType(t Type, f func(Type) Type) Type {
+	switch t := t.(type) {
+	case List:
+		return List{mapType(t.Elem, f)}
+
+	case Func:
+		ret := Func{
+			Params:   make([]Type, len(t.Params)),
+			Min:      t.Min,
+			Variadic: t.Variadic,
+			Result:   mapType(t.Result, f),
+		}
+		for i, p := range t.Params {
+			ret.Params[i] = mapType(p, f)
+		}
+		return ret
+
+	default:
+		return f(t)
+	}
+}
+
+func numeric(t Type) bool {
+	return t == Int || t == Float || t == Number
+}
+
+// Consistent reports whether a value of type a may be used where b is
+// expected. Any is consistent with every type, and number with int and
+// float.
+func Consistent(a, b Type) bool {
+	if a == Any || b == Any {
+		return true
+	}
+
+	switch a := a.(type) {
+	case Basic:
+		b, ok := b.(Basic)
+		return ok && (a == b || a == Number && numeric(b) || b == Number && numeric(a))
+
+	case List:
+		b, ok := b.(List)
+		return ok && Consistent(a.Elem, b.Elem)
+
+	case Func:
+		b, ok := b.(Func)
+		if !ok || !Consistent(a.Result, b.Result) {
+			return false
+		}
+		if !a.Variadic && !b.Variadic && len(a.Params) != len(b.Params) {
+			return false
+		}
+		for i := range min(len(a.Params), len(b.Params)) {
+			if !Consistent(a.Params[i], b.Params[i]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+// join returns the type of a value that is either of type a or of type
+// b. A nil type stands for no value at all, such as the value of a block
+// ending in a return.
+func join(a, b Type) Type {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.String() == b.String():
+		return a
+	case numeric(a) && numeric(b):
+		return Number
+	}
+
+	if la, ok := a.(List); ok {
+		if lb, ok := b.(List); ok {
+			return List{join(la.Elem, lb.Elem)}
+		}
+	}
+	return Any
+}
+
+var typeNames = map[string]Type{
+	"any":    Any,
+	"int":    Int,
+	"float":  Float,
+	"number": Number,
+	"bool":   Bool,
+	"string": String,
+	"null":   Null,
+	"error":  Error,
+	"vector": Vector,
+	"cons":   Cons,
+	"object": Object,
+	"list":   List{Any},
+}
+
+// FromAnnotation returns the type written in an annotation.
+func FromAnnotation(t *patukek_ast.TypeExpr) (Type, error) {
+	return convert(t, nil)
+}
+
+// convert returns the type written in t. Names that are not types are
+// looked up with tvar, when it is not nil, as type variables.
+func convert(t *patukek_ast.TypeExpr, tvar func(name string) Type) (Type, error) {
+	switch {
+	case t.Name == "patukek":
+		f := Func{Result: Any}
+		for i, p := range t.Params {
+			switch {
+			case p.Variadic && i < len(t.Params)-1:
+				return nil, fmt.Errorf("only the last parameter of %v can be variadic", t)
+			case p.Variadic:
+				f.Variadic = true
+			case p.Optional:
+			case f.Min < i:
+				return nil, fmt.Errorf("a required parameter of %v follows an optional one", t)
+			default:
+				f.Min++
+			}
+
+			pt, err := convert(p, tvar)
+			if err != nil {
+				return nil, err
+			}
+			f.Params = append(f.Params, pt)
+		}
+
+		if t.Result != nil {
+			r, err := convert(t.Result, tvar)
+			if err != nil {
+				return nil, err
+			}
+			f.Result = r
+		}
+		return f, nil
+
+	case t.Elem != nil:
+		if t.Name != "list" {
+			return nil, fmt.Errorf("type %s has no element type", t.Name)
+		}
+		e, err := convert(t.Elem, tvar)
+		if err != nil {
+			return nil, err
+		}
+		return List{e}, nil
+
+	default:
+		if ret, ok := typeNames[t.Name]; ok {
+			return ret, nil
+		}
+		if tvar != nil {
+			return tvar(t.Name), nil
+		}
+		return nil, fmt.Errorf("unknown type %s", t.Name)
+	}
+}
+
+// builtinTypes holds the type schemes declared by the builtins, for
+// Infer. Their type variables have negative IDs, so that they never clash
+// with those of an inference.
+var builtinTypes = func() map[string]Type {
+	ret := make(map[string]Type, len(patukek_obj.Builtins))
+	for _, b := range patukek_obj.Builtins {
+		ret[b.Name] = builtinType(b)
+	}
+	return ret
+}()
+
+func builtinType(b patukek_obj.BuiltinImpl) Type {
+	expr, err := patukek_parser.ParseType(b.Type)
+	if err != nil {
+		panic(fmt.Sprintf("type of builtin %s: %v", b.Name, err))
+	}
+
+	vars := make(map[string]Var)
+	t, err := convert(expr, func(name string) Type {
+		v, ok := vars[name]
+		if !ok {
+			v = Var{ID: -len(vars) - 1}
+			vars[name] = v
+		}
+		return v
+	})
+	if err != nil {
+		panic(fmt.Sprintf("type of builtin %s: %v", b.Name, err))
+	}
+	return t
+}
+
+// signatureType derives the type of a builtin from its documented
+// signature, such as "len(x) int" or "range([start, ]stop[, step]) list".
+// Parameters in brackets are optional and a parameter followed by "..."
+// is variadic. Parameters are untyped, and so are results the signature
+// does not name. Check uses these rather than the declared type schemes,
+// which are stricter than the builtins, such as append on vectors.
+func signatureType(sig string) Type {
+	open, close := strings.IndexByte(sig, '('), strings.LastIndexByte(sig, ')')
+	if open < 0 || close < open {
+		// A value, such as "pi float".
+		_, res, _ := strings.Cut(sig, " ")
+		return resultType(res)
+	}
+
+	var (
+		f     = Func{Result: resultType(strings.TrimSpace(sig[close+1:]))}
+		depth int
+		alt   bool
+		word  int
+	)
+
+	params := sig[open+1 : close]
+	for i := 0; i <= len(params); i++ {
+		var b byte
+		if i < len(params) {
+			b = params[i]
+		}
+
+		if b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' {
+			word++
+			continue
+		}
+
+		if word > 0 {
+			word = 0
+			switch {
+			case alt:
+				// The second name of "fn | value" is the same parameter.
+				alt = false
+			case strings.HasPrefix(params[i:], "..."):
+				f.Params = append(f.Params, Any)
+				f.Variadic = true
+			default:
+				f.Params = append(f.Params, Any)
+				if depth == 0 {
+					f.Min++
+				}
+			}
+		}
+
+		switch b {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '|':
+			alt = true
+		}
+	}
+	return f
+}
+
+func resultType(name string) Type {
+	if t, ok := typeNames[name]; ok {
+		return t
+	}
+	return Any
+}
@@ -0,0 +1,188 @@
+package patukek_vet
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_obj"
+)
+
+func unusedVars(p *pass) {
+	for _, v := range p.sortedVars() {
+		if v.param || len(v.reads) > 0 || len(v.writes) == 0 || ignored(v.name) {
+			continue
+		}
+		// Test functions are called by the test runner.
+		if v.global && strings.HasPrefix(v.name, "test_") {
+			continue
+		}
+		p.report(v.def, fmt.Sprintf("%s is assigned but never used", v.name))
+	}
+}
+
+func unusedParams(p *pass) {
+	for _, v := range p.sortedVars() {
+		if v.param && len(v.reads) == 0 && !ignored(v.name) {
+			p.report(v.def, fmt.Sprintf("parameter %s is never used", v.name))
+		}
+	}
+}
+
+// deadStores finds assignments whose value cannot be read: those followed
+// in the same block by another assignment to the variable with no read in
+// between, and, for local variables, those with no read after them at all.
+// Globals may be read by any function, so a call in between counts as a
+// read.
+func deadStores(p *pass) {
+	patukek_ast.Inspect(p.tree, func(n patukek_ast.Node) bool {
+		b, ok := n.(*patukek_ast.Block)
+		if !ok {
+			return true
+		}
+
+		for i, s := range b.Nodes {
+			a, v, ok := p.assignment(s)
+			if !ok || len(v.reads) == 0 {
+				continue
+			}
+
+			if next, ok := p.overwrite(v, b.Nodes[i+1:]); ok {
+				p.report(a.Left(), fmt.Sprintf("value assigned to %s is overwritten on line %d before being read",
+					v.name, p.lines.Position(next.Pos()).Line))
+			} else if !v.global && !v.readIn(a.End(), math.MaxInt) {
+				p.report(a.Left(), fmt.Sprintf("value assigned to %s is never read", v.name))
+			}
+		}
+		return true
+	})
+}
+
+// assignment returns the assignment to a variable that n is, if any.
+func (p *pass) assignment(n patukek_ast.Node) (patukek_ast.Assign, *variable, bool) {
+	a, ok := n.(patukek_ast.Assign)
+	if !ok {
+		return a, nil, false
+	}
+	i, ok := a.Left().(patukek_ast.Identifier)
+	if !ok {
+		return a, nil, false
+	}
+	v, ok := p.variable(i)
+	return a, v, ok
+}
+
+// overwrite returns the first of stmts that assigns v again before any
+// of them may read it.
+func (p *pass) overwrite(v *variable, stmts []patukek_ast.Node) (patukek_ast.Node, bool) {
+	for _, s := range stmts {
+		if v.readIn(s.Pos(), s.End()) {
+			return nil, false
+		}
+		if _, ok := s.(patukek_ast.Return); ok {
+			return nil, false
+		}
+		if v.global && hasCall(s) {
+			return nil, false
+		}
+		if _, w, ok := p.assignment(s); ok && w == v {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func hasCall(n patukek_ast.Node) bool {
+	var found bool
+	patukek_ast.Inspect(n, func(n patukek_ast.Node) bool {
+		if _, ok := n.(patukek_ast.Call); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// arity checks calls to functions whose definition is known: literals
+// called directly and variables assigned a function only once.
+func arity(p *pass) {
+	patukek_ast.Inspect(p.tree, func(n patukek_ast.Node) bool {
+		c, ok := n.(patukek_ast.Call)
+		if !ok {
+			return true
+		}
+
+		var (
+			fn   patukek_ast.Function
+			name = "function"
+		)
+		switch callee := c.Fn.(type) {
+		case patukek_ast.Function:
+			fn = callee
+
+		case patukek_ast.Identifier:
+			v, ok := p.variable(callee)
+			if !ok || v.param || len(v.writes) != 1 {
+				return true
+			}
+			if fn, ok = v.writes[0].Right().(patukek_ast.Function); !ok {
+				return true
+			}
+			name = v.name
+
+		default:
+			return true
+		}
+
+		if want := len(fn.Params()); want != len(c.Args) {
+			p.report(c, fmt.Sprintf("%s takes %d %s but is called with %d", name, want, plural(want, "argument"), len(c.Args)))
+		}
+		return true
+	})
+}
+
+func unreachable(p *pass) {
+	patukek_ast.Inspect(p.tree, func(n patukek_ast.Node) bool {
+		b, ok := n.(*patukek_ast.Block)
+		if !ok {
+			return true
+		}
+
+		for i, s := range b.Nodes[:max(len(b.Nodes)-1, 0)] {
+			if _, ok := s.(patukek_ast.Return); ok {
+				p.report(b.Nodes[i+1], "unreachable code after return")
+				break
+			}
+		}
+		return true
+	})
+}
+
+func shadowBuiltins(p *pass) {
+	builtins := make(map[string]bool)
+	for _, b := range patukek_obj.Builtins {
+		builtins[b.Name] = true
+	}
+
+	for _, v := range p.sortedVars() {
+		// A top-level definition replaces the builtin for the whole file,
+		// which is deliberate; parameters and locals hide it by accident.
+		if !builtins[v.name] || v.global {
+			continue
+		}
+
+		if v.param {
+			p.report(v.def, fmt.Sprintf("parameter %s shadows the builtin function", v.name))
+		} else {
+			p.report(v.def, fmt.Sprintf("%s shadows the builtin function", v.name))
+		}
+	}
+}
+
+func plural(n int, s string) string {
+	if n == 1 {
+		return s
+	}
+	return s + "s"
+}
@@ -0,0 +1,219 @@
+package patukek_vet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_build"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_err"
+	"patukek/internal/patukek_parser"
+)
+
+// Check is a single analysis. Its ID names it on the command line and
+// in reports.
+type Check struct {
+	ID  string
+	Doc string
+	run func(p *pass)
+}
+
+// Checks lists every check in the order they run.
+var Checks = []Check{
+	{ID: "unused-var", Doc: "variables that are assigned but never read", run: unusedVars},
+	{ID: "unused-param", Doc: "function parameters that are never read", run: unusedParams},
+	{ID: "dead-store", Doc: "assigned values that are overwritten or go out of scope unread", run: deadStores},
+	{ID: "arity", Doc: "calls with a different number of arguments than the function's parameters", run: arity},
+	{ID: "unreachable", Doc: "statements following a return", run: unreachable},
+	{ID: "shadow-builtin", Doc: "parameters and local variables named like a builtin", run: shadowBuiltins},
+}
+
+// Diagnostic is a problem found by a check.
+type Diagnostic struct {
+	Check   string           `json:"check"`
+	File    string           `json:"file"`
+	Span    patukek_err.Span `json:"span"`
+	Message string           `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s (%s)", d.File, d.Span.Start.Line, d.Span.Start.Column, d.Message, d.Check)
+}
+
+// Enabled returns the set of checks selected by the comma-separated
+// lists of IDs of patukek vet's -checks and -disable flags: the checks
+// listed in checks, or all of them if it is empty, except those listed in
+// disable.
+func Enabled(checks, disable string) (map[string]bool, error) {
+	ret := make(map[string]bool)
+	for _, c := range Checks {
+		ret[c.ID] = checks == ""
+	}
+
+	for _, list := range []struct {
+		ids string
+		on  bool
+	}{{checks, true}, {disable, false}} {
+		for _, id := range strings.Split(list.ids, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			if _, ok := ret[id]; !ok {
+				return nil, fmt.Errorf("unknown check %q", id)
+			}
+			ret[id] = list.on
+		}
+	}
+	return ret, nil
+}
+
+// Vet runs the checks whose ID is in enabled, or all of them if enabled
+// is nil, on a source file. Programs that do not compile are reported
+// as an error.
+func Vet(file, src string, enabled map[string]bool) ([]Diagnostic, error) {
+	tree, errs := patukek_parser.Parse(file, src)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	c := patukek_compiler.New()
+	c.TrackReferences()
+	if _, err := patukek_build.Compile(c, file, src, tree); err != nil {
+		return nil, err
+	}
+
+	p := newPass(file, src, tree, c.References)
+	for _, ch := range Checks {
+		if enabled == nil || enabled[ch.ID] {
+			p.check = ch.ID
+			ch.run(p)
+		}
+	}
+
+	sort.SliceStable(p.diags, func(i, j int) bool {
+		return p.diags[i].Span.Start.Offset < p.diags[j].Span.Start.Offset
+	})
+	return p.diags, nil
+}
+
+// variable is everything known about a variable, identified by the
+// offset of its first definition.
+type variable struct {
+	name   string
+	def    patukek_ast.Identifier
+	param  bool
+	global bool
+	writes []patukek_ast.Assign
+	reads  []int
+}
+
+type pass struct {
+	file   string
+	tree   patukek_ast.Node
+	lines  *patukek_err.Lines
+	check  string
+	diags  []Diagnostic
+	vars   map[int]*variable
+	refs   map[int]patukek_compiler.Reference
+	params map[int]patukek_ast.Identifier
+}
+
+func newPass(file, src string, tree patukek_ast.Node, refs []patukek_compiler.Reference) *pass {
+	p := &pass{
+		file:   file,
+		tree:   tree,
+		lines:  patukek_err.NewLines(src),
+		vars:   make(map[int]*variable),
+		refs:   make(map[int]patukek_compiler.Reference),
+		params: make(map[int]patukek_ast.Identifier),
+	}
+
+	targets := make(map[int]patukek_ast.Assign)
+	patukek_ast.Inspect(tree, func(n patukek_ast.Node) bool {
+		switch n := n.(type) {
+		case patukek_ast.Assign:
+			if i, ok := n.Left().(patukek_ast.Identifier); ok {
+				targets[i.Pos()] = n
+			}
+		case patukek_ast.Function:
+			for _, i := range n.Params() {
+				p.params[i.Pos()] = i
+			}
+		}
+		return true
+	})
+
+	for _, r := range refs {
+		if r.Symbol.Scope == patukek_compiler.BuiltinScope {
+			continue
+		}
+		p.refs[r.Pos] = r
+
+		v, ok := p.vars[r.Symbol.Pos]
+		if !ok {
+			v = &variable{name: r.Name, global: r.Symbol.Scope == patukek_compiler.GlobalScope}
+			p.vars[r.Symbol.Pos] = v
+		}
+
+		if a, ok := targets[r.Pos]; ok {
+			v.writes = append(v.writes, a)
+			if r.Pos == r.Symbol.Pos {
+				v.def = a.Left().(patukek_ast.Identifier)
+			}
+		} else if i, ok := p.params[r.Pos]; ok {
+			v.param, v.def = true, i
+		} else {
+			v.reads = append(v.reads, r.Pos)
+		}
+	}
+	return p
+}
+
+func (p *pass) report(n patukek_ast.Node, msg string) {
+	p.diags = append(p.diags, Diagnostic{
+		Check:   p.check,
+		File:    p.file,
+		Span:    patukek_ast.SpanOf(n, p.lines),
+		Message: msg,
+	})
+}
+
+// sortedVars returns the variables in the order of their definitions.
+func (p *pass) sortedVars() []*variable {
+	var ret []*variable
+	for _, v := range p.vars {
+		ret = append(ret, v)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].def.Pos() < ret[j].def.Pos()
+	})
+	return ret
+}
+
+// variable returns the variable an identifier refers to.
+func (p *pass) variable(i patukek_ast.Identifier) (*variable, bool) {
+	r, ok := p.refs[i.Pos()]
+	if !ok {
+		return nil, false
+	}
+	v, ok := p.vars[r.Symbol.Pos]
+	return v, ok
+}
+
+// readIn reports whether v is read between the offsets start and end.
+func (v *variable) readIn(start, end int) bool {
+	for _, r := range v.reads {
+		if start <= r && r < end {
+			return true
+		}
+	}
+	return false
+}
+
+// ignored reports whether a name opts out of the unused checks.
+func ignored(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
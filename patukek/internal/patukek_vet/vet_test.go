@@ -0,0 +1,187 @@
+package patukek_vet
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"testing"
+)
+
+func vet(t *testing.T, src string, enabled map[string]bool) []string {
+	t.Helper()
+
+	diags, err := Vet("test.ptk", src, enabled)
+	if err != nil {
+		t.Fatalf("Vet(%q): %v", src, err)
+	}
+
+	var ret []string
+	for _, d := range diags {
+		ret = append(ret, d.String())
+	}
+	return ret
+}
+
+func TestChecks(t *testing.T) {
+	tests := []struct {
+		check string
+		src   string
+		want  []string
+	}{
+		{
+			check: "unused-var",
+			src:   "x = 1\n_y = 2\ntest_one = patukek() { 1 }\nprintln(3)",
+			want:  []string{"test.ptk:1:1: x is assigned but never used (unused-var)"},
+		},
+		{
+			check: "unused-param",
+			src:   "f = patukek(a, b, _c) { a }\nprintln(f(1, 2, 3))",
+			want:  []string{"test.ptk:1:16: parameter b is never used (unused-param)"},
+		},
+		{
+			check: "dead-store",
+			src:   "f = patukek() {\n\tx = 1\n\tx = 2\n\tprintln(x)\n\tx = 3\n}\nf()",
+			want: []string{
+				"test.ptk:2:2: value assigned to x is overwritten on line 3 before being read (dead-store)",
+				"test.ptk:5:2: value assigned to x is never read (dead-store)",
+			},
+		},
+		{
+			check: "arity",
+			src:   "f = patukek(a) { a }\nprintln(f(1, 2), f(1), patukek() { 1 }(2))",
+			want: []string{
+				"test.ptk:2:9: f takes 1 argument but is called with 2 (arity)",
+				"test.ptk:2:24: function takes 0 arguments but is called with 1 (arity)",
+			},
+		},
+		{
+			check: "unreachable",
+			src:   "f = patukek() {\n\treturn 1\n\tprintln(2)\n\tprintln(3)\n}\nf()",
+			want:  []string{"test.ptk:3:2: unreachable code after return (unreachable)"},
+		},
+		{
+			check: "shadow-builtin",
+			src:   "len = 1\nf = patukek(max) {\n\tmin = max\n\tmin\n}\nprintln(len, f(1))",
+			want: []string{
+				"test.ptk:2:13: parameter max shadows the builtin function (shadow-builtin)",
+				"test.ptk:3:2: min shadows the builtin function (shadow-builtin)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.check, func(t *testing.T) {
+			got := vet(t, tt.src, map[string]bool{tt.check: true})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	ids := make(map[string]bool)
+	for _, c := range Checks {
+		ids[c.ID] = true
+	}
+	for _, tt := range tests {
+		delete(ids, tt.check)
+	}
+	for id := range ids {
+		t.Errorf("check %s is not tested", id)
+	}
+}
+
+func TestVetAll(t *testing.T) {
+	got := vet(t, "x = 1\nf = patukek(len) { return 1\n1 }", nil)
+	want := []string{
+		"test.ptk:1:1: x is assigned but never used (unused-var)",
+		"test.ptk:2:1: f is assigned but never used (unused-var)",
+		"test.ptk:2:13: parameter len is never used (unused-param)",
+		"test.ptk:2:13: parameter len shadows the builtin function (shadow-builtin)",
+		"test.ptk:3:1: unreachable code after return (unreachable)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestVetErrors(t *testing.T) {
+	for _, src := range []string{"x = (", "println(y)"} {
+		if _, err := Vet("test.ptk", src, nil); err == nil {
+			t.Errorf("Vet(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	all := make(map[string]bool)
+	for _, c := range Checks {
+		all[c.ID] = true
+	}
+	with := func(on bool, ids ...string) map[string]bool {
+		ret := maps.Clone(all)
+		for id := range ret {
+			ret[id] = !on
+		}
+		for _, id := range ids {
+			ret[id] = on
+		}
+		return ret
+	}
+
+	tests := []struct {
+		checks, disable string
+		want            map[string]bool
+	}{
+		{"", "", all},
+		{"arity", "", with(true, "arity")},
+		{"arity, unreachable", "", with(true, "arity", "unreachable")},
+		{"", "shadow-builtin,unused-var", with(false, "shadow-builtin", "unused-var")},
+		{"arity,unreachable", "arity", with(true, "unreachable")},
+	}
+
+	for _, tt := range tests {
+		got, err := Enabled(tt.checks, tt.disable)
+		if err != nil {
+			t.Errorf("Enabled(%q, %q): %v", tt.checks, tt.disable, err)
+		} else if !maps.Equal(got, tt.want) {
+			t.Errorf("Enabled(%q, %q) = %v, want %v", tt.checks, tt.disable, got, tt.want)
+		}
+	}
+
+	for _, args := range [][2]string{{"nope", ""}, {"", "arity,nope"}} {
+		if _, err := Enabled(args[0], args[1]); err == nil {
+			t.Errorf("Enabled(%q, %q) accepted an unknown check", args[0], args[1])
+		}
+	}
+}
+
+// TestJSON pins the shape of patukek vet -json, which editors parse.
+func TestJSON(t *testing.T) {
+	diags, err := Vet("test.ptk", "x = 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{map[string]any{
+		"check": "unused-var",
+		"file":  "test.ptk",
+		"span": map[string]any{
+			"start": map[string]any{"offset": 0.0, "line": 1.0, "column": 1.0},
+			"end":   map[string]any{"offset": 1.0, "line": 1.0, "column": 2.0},
+		},
+		"message": "x is assigned but never used",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s, want %v", b, want)
+	}
+}
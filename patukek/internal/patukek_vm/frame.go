@@ -21,4 +21,10 @@ func NewFrame(cl *patukek_obj.Closure, basePointer int) *Frame {
 
 func (f *Frame) Instructions() patukek_code.Instructions {
 	return f.cl.Fn.Instructions
-}
\ No newline at end of file
+}
+
+// done reports whether the frame has executed all its instructions, as
+// the main frame has when a function is called after Run returned.
+func (f *Frame) done() bool {
+	return f.ip >= len(f.Instructions())-1
+}
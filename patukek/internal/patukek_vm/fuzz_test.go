@@ -0,0 +1,42 @@
+package patukek_vm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_parser"
+	"patukek/internal/patukek_seed"
+)
+
+// FuzzRun runs programs under an instruction and allocation budget, so
+// any input must either finish or fail with an error.
+func FuzzRun(f *testing.F) {
+	patukek_seed.AddExamples(f)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		tree, errs := patukek_parser.Parse("fuzz.ptk", in)
+		if len(errs) > 0 {
+			return
+		}
+
+		c := patukek_compiler.New()
+		c.SetFileInfo("fuzz.ptk", in)
+		if err := c.Compile(tree); err != nil {
+			return
+		}
+
+		vm := NewWithState("fuzz.ptk", c.Bytecode(), NewState(), Config{
+			Stdin:           strings.NewReader(""),
+			Stdout:          io.Discard,
+			Stderr:          io.Discard,
+			MaxInstructions: 100_000,
+			MaxAllocSize:    1 << 16,
+			Timeout:         time.Second,
+		})
+		_ = vm.Run(context.Background())
+	})
+}
@@ -0,0 +1,97 @@
+package patukek_vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"patukek/internal/patukek_obj"
+)
+
+type LimitKind int
+
+const (
+	InstructionLimit LimitKind = iota
+	TimeLimit
+	AllocLimit
+	Canceled
+)
+
+const checkInterval = 1024
+
+var errTimeout = errors.New("timeout")
+
+type LimitError struct {
+	Kind  LimitKind
+	Limit int64
+	Err   error
+}
+
+func (e *LimitError) Error() string {
+	switch e.Kind {
+	case InstructionLimit:
+		return fmt.Sprintf("instruction limit of %d exceeded", e.Limit)
+	case TimeLimit:
+		return "time limit exceeded"
+	case AllocLimit:
+		return fmt.Sprintf("allocation of more than %d elements", e.Limit)
+	default:
+		return fmt.Sprintf("execution canceled: %v", e.Err)
+	}
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+func (vm *VM) checkLimits() error {
+	if vm.halted != nil {
+		return vm.halted
+	}
+
+	if vm.maxSteps > 0 && vm.steps > vm.maxSteps {
+		return vm.halt(&LimitError{Kind: InstructionLimit, Limit: vm.maxSteps})
+	}
+
+	select {
+	case <-vm.ctx.Done():
+		if context.Cause(vm.ctx) == errTimeout {
+			return vm.halt(&LimitError{Kind: TimeLimit, Limit: int64(vm.timeout), Err: context.DeadlineExceeded})
+		}
+		return vm.halt(&LimitError{Kind: Canceled, Err: vm.ctx.Err()})
+	default:
+	}
+
+	vm.nextCheck = vm.steps + checkInterval
+	if vm.maxSteps > 0 && vm.nextCheck > vm.maxSteps+1 {
+		vm.nextCheck = vm.maxSteps + 1
+	}
+	return nil
+}
+
+func (vm *VM) checkSize(o patukek_obj.Object) error {
+	switch o := o.(type) {
+	case patukek_obj.List:
+		return vm.CheckSize(len(o))
+	case patukek_obj.String:
+		return vm.CheckSize(len(o))
+	case *patukek_obj.Vector:
+		return vm.CheckSize(o.Len())
+	case *patukek_obj.Cons:
+		return vm.CheckSize(o.Len())
+	default:
+		return nil
+	}
+}
+
+func (vm *VM) CheckSize(n int) error {
+	if vm.maxAlloc > 0 && n > vm.maxAlloc {
+		return vm.halt(&LimitError{Kind: AllocLimit, Limit: int64(vm.maxAlloc)})
+	}
+	return nil
+}
+
+func (vm *VM) halt(err error) error {
+	vm.halted = err
+	return err
+}
@@ -0,0 +1,108 @@
+package patukek_vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// busy runs for much longer than the tests wait, without deep recursion
+// or large allocations.
+const busy = `f = patukek(i) { map(range(1000), patukek(j) { map(range(1000), patukek(k) { k }) }) }
+map(range(1000), f)`
+
+func limitError(t *testing.T, err error, kind LimitKind) *LimitError {
+	t.Helper()
+
+	var lerr *LimitError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("got %v, want a limit error", err)
+	}
+	if lerr.Kind != kind {
+		t.Fatalf("got a limit error of kind %d (%v), want kind %d", lerr.Kind, lerr, kind)
+	}
+	return lerr
+}
+
+func TestInstructionLimit(t *testing.T) {
+	for _, src := range []string{"loop = patukek(n) { loop(n + 1) }\nloop(0)", busy} {
+		err := newVM(t, src, Config{MaxInstructions: 1000}).Run(context.Background())
+		if lerr := limitError(t, err, InstructionLimit); lerr.Limit != 1000 {
+			t.Errorf("Limit = %d, want 1000", lerr.Limit)
+		}
+	}
+
+	if _, err := runErr(t, "x = 1 + 2"); err != nil {
+		t.Errorf("a program without limits failed: %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	start := time.Now()
+	err := newVM(t, busy, Config{Timeout: 10 * time.Millisecond}).Run(context.Background())
+
+	lerr := limitError(t, err, TimeLimit)
+	if lerr.Limit != int64(10*time.Millisecond) {
+		t.Errorf("Limit = %d, want %d", lerr.Limit, 10*time.Millisecond)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v does not wrap context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("the program stopped after %v", d)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := newVM(t, busy, Config{}).Run(ctx)
+	limitError(t, err, Canceled)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("%v does not wrap context.Canceled", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	limitError(t, newVM(t, busy, Config{}).Run(ctx), Canceled)
+
+	// A deadline of the caller is a cancellation, not the VM timeout.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = newVM(t, busy, Config{Timeout: time.Hour}).Run(ctx)
+	limitError(t, err, Canceled)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v does not wrap context.DeadlineExceeded", err)
+	}
+}
+
+func TestMaxAllocSize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"list literal", "x = [1, 2, 3, 4, 5, 6]"},
+		{"builtin result", `x = split("a b c d e f")`},
+		{"flat_map", "x = flat_map([1, 2], patukek(i) { [i, i, i] })"},
+		{"string concatenation", `x = "abc" + "def"`},
+		{"interpolation", "x = \"abc\"\ny = \"{x}{x}\""},
+		{"range", "x = range(6)"},
+		{"repeat", `x = repeat("ab", 3)`},
+		{"in a callback", "x = map([1], patukek(i) { range(10) })"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newVM(t, tt.src, Config{MaxAllocSize: 5}).Run(context.Background())
+			if lerr := limitError(t, err, AllocLimit); lerr.Limit != 5 {
+				t.Errorf("Limit = %d, want 5", lerr.Limit)
+			}
+
+			if err := newVM(t, tt.src, Config{MaxAllocSize: 10}).Run(context.Background()); err != nil {
+				t.Errorf("with a limit of 10: %v", err)
+			}
+		})
+	}
+}
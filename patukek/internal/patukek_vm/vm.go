@@ -5,14 +5,25 @@ import (
 	"patukek/internal/patukek_compiler"
 	"patukek/internal/patukek_err"
 	"patukek/internal/patukek_obj"
+	"patukek/internal/patukek_suggest"
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
 	"path/filepath"
+	"time"
 )
 
 type State struct {
-	Symbols *patukek_compiler.SymbolTable
-	Consts  []patukek_obj.Object
-	Globals []patukek_obj.Object
+	Symbols  *patukek_compiler.SymbolTable
+	Consts   []patukek_obj.Object
+	Globals  []patukek_obj.Object
+	Builtins []patukek_obj.BuiltinImpl
+	Rand     *rand.Rand
+	source   *rand.PCG
 }
 
 func NewState() *State {
@@ -21,28 +32,81 @@ func NewState() *State {
 		st.DefineBuiltin(i, builtin.Name)
 	}
 
+	src := rand.NewPCG(0, 0)
 	return &State{
-		Consts:  []patukek_obj.Object{},
-		Globals: make([]patukek_obj.Object, GlobalSize),
-		Symbols: st,
+		Consts:   []patukek_obj.Object{},
+		Globals:  make([]patukek_obj.Object, GlobalSize),
+		Symbols:  st,
+		Builtins: append([]patukek_obj.BuiltinImpl{}, patukek_obj.Builtins...),
+		Rand:     rand.New(src),
+		source:   src,
 	}
 }
 
+func (s *State) Seed(seed uint64) {
+	s.source.Seed(seed, seed)
+}
+
+func (s *State) DefineBuiltin(name string, fn patukek_obj.Builtin) error {
+	if sym, ok := s.Symbols.Store[name]; ok && sym.Scope == patukek_compiler.BuiltinScope {
+		s.Builtins[sym.Index] = patukek_obj.BuiltinImpl{Name: name, Builtin: fn}
+		return nil
+	}
+
+	if len(s.Builtins) >= MaxBuiltins {
+		return fmt.Errorf("too many builtins: the limit is %d", MaxBuiltins)
+	}
+
+	s.Builtins = append(s.Builtins, patukek_obj.BuiltinImpl{Name: name, Builtin: fn})
+	s.Symbols.DefineBuiltin(len(s.Builtins)-1, name)
+	return nil
+}
+
+func (s *State) RegisterFunc(name string, fn any) error {
+	b, err := patukek_obj.NewNativeFunc(name, fn)
+	if err != nil {
+		return err
+	}
+	return s.DefineBuiltin(name, b)
+}
+
+type Config struct {
+	Stdin           io.Reader
+	Stdout          io.Writer
+	Stderr          io.Writer
+	StackSize       int
+	MaxFrames       int
+	MaxInstructions int64
+	MaxAllocSize    int
+	Timeout         time.Duration
+}
+
 type VM struct {
 	*State
 	dir        string
 	file       string
+	stdin      *bufio.Reader
+	stdout     io.Writer
+	stderr     io.Writer
 	stack      []patukek_obj.Object
 	frames     []*Frame
 	localTable []bool
 	sp         int
 	frameIndex int
+	ctx        context.Context
+	steps      int64
+	nextCheck  int64
+	maxSteps   int64
+	maxAlloc   int
+	timeout    time.Duration
+	halted     error
 }
 
 const (
-	StackSize  = 2048
-	GlobalSize = 65536
-	MaxFrames  = 1024
+	StackSize   = 2048
+	GlobalSize  = 65536
+	MaxFrames   = 1024
+	MaxBuiltins = 256
 )
 
 var (
@@ -52,12 +116,39 @@ var (
 )
 
 func New(file string, bytecode *patukek_compiler.Bytecode) *VM {
+	return NewWithState(file, bytecode, NewState(), Config{})
+}
+
+func NewWithState(file string, bytecode *patukek_compiler.Bytecode, s *State, cfg Config) *VM {
+	if cfg.StackSize <= 0 {
+		cfg.StackSize = StackSize
+	}
+	if cfg.MaxFrames <= 0 {
+		cfg.MaxFrames = MaxFrames
+	}
+	if cfg.Stdin == nil {
+		cfg.Stdin = os.Stdin
+	}
+	if cfg.Stdout == nil {
+		cfg.Stdout = os.Stdout
+	}
+	if cfg.Stderr == nil {
+		cfg.Stderr = os.Stderr
+	}
+
 	vm := &VM{
-		stack:      make([]patukek_obj.Object, StackSize),
-		frames:     make([]*Frame, MaxFrames),
+		stack:      make([]patukek_obj.Object, cfg.StackSize),
+		frames:     make([]*Frame, cfg.MaxFrames),
 		frameIndex: 1,
 		localTable: make([]bool, GlobalSize),
-		State:      NewState(),
+		stdin:      bufio.NewReader(cfg.Stdin),
+		stdout:     cfg.Stdout,
+		stderr:     cfg.Stderr,
+		ctx:        context.Background(),
+		maxSteps:   cfg.MaxInstructions,
+		maxAlloc:   cfg.MaxAllocSize,
+		timeout:    cfg.Timeout,
+		State:      s,
 	}
 
 	vm.dir, vm.file = filepath.Split(file)
@@ -70,13 +161,41 @@ func New(file string, bytecode *patukek_compiler.Bytecode) *VM {
 	return vm
 }
 
+func (vm *VM) Stdin() *bufio.Reader {
+	return vm.stdin
+}
+
+func (vm *VM) Stdout() io.Writer {
+	return vm.stdout
+}
+
+func (vm *VM) Stderr() io.Writer {
+	return vm.stderr
+}
+
+func (vm *VM) Rand() *rand.Rand {
+	return vm.State.Rand
+}
+
+func (vm *VM) LastPopped() patukek_obj.Object {
+	if o := vm.stack[vm.sp]; o != nil {
+		return o
+	}
+	return Null
+}
+
 func (vm *VM) currentFrame() *Frame {
 	return vm.frames[vm.frameIndex-1]
 }
 
-func (vm *VM) pushFrame(f *Frame) {
+func (vm *VM) pushFrame(f *Frame) error {
+	if vm.frameIndex >= len(vm.frames) {
+		return vm.errorf("maximum call depth exceeded")
+	}
+
 	vm.frames[vm.frameIndex] = f
 	vm.frameIndex++
+	return nil
 }
 
 func (vm *VM) popFrame() *Frame {
@@ -85,8 +204,25 @@ func (vm *VM) popFrame() *Frame {
 }
 
 func (vm *VM) bookmark() patukek_err.Bookmark {
+	return bookmarkAt(vm.currentFrame())
+}
+
+func (vm *VM) trace() []patukek_err.Bookmark {
+	var ret []patukek_err.Bookmark
+
+	for i := vm.frameIndex - 2; i >= 0; i-- {
+		if vm.frames[i].done() {
+			continue
+		}
+		if b := bookmarkAt(vm.frames[i]); b != (patukek_err.Bookmark{}) {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+func bookmarkAt(frame *Frame) patukek_err.Bookmark {
 	var (
-		frame     = vm.currentFrame()
 		offset    = frame.ip
 		bookmarks = frame.cl.Fn.Bookmarks
 	)
@@ -108,14 +244,21 @@ func (vm *VM) bookmark() patukek_err.Bookmark {
 }
 
 func (vm *VM) errorf(s string, a ...any) error {
-	return patukek_err.NewFromBookmark(
+	return patukek_err.NewWithTrace(
 		filepath.Join(vm.dir, vm.file),
 		vm.bookmark(),
+		vm.trace(),
 		s,
 		a...,
 	)
 }
 
+func isFloatOp(left, right patukek_obj.Object) bool {
+	return patukek_obj.AssertTypes(left, patukek_obj.IntType, patukek_obj.FloatType) &&
+		patukek_obj.AssertTypes(right, patukek_obj.IntType, patukek_obj.FloatType) &&
+		(left.Type() == patukek_obj.FloatType || right.Type() == patukek_obj.FloatType)
+}
+
 func (vm *VM) execAdd() error {
 	var (
 		right = patukek_obj.Unwrap(vm.pop())
@@ -128,9 +271,17 @@ func (vm *VM) execAdd() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(l + r)
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.Float(l + r))
+
 	case patukek_obj.AssertTypes(left, patukek_obj.StringType) && patukek_obj.AssertTypes(right, patukek_obj.StringType):
 		l := left.(patukek_obj.String)
 		r := right.(patukek_obj.String)
+		if err := vm.checkSize(l + r); err != nil {
+			return err
+		}
 		return vm.push(l + r)
 
 	default:
@@ -138,6 +289,19 @@ func (vm *VM) execAdd() error {
 	}
 }
 
+func (vm *VM) execMinus() error {
+	switch o := patukek_obj.Unwrap(vm.pop()).(type) {
+	case patukek_obj.Integer:
+		return vm.push(-o)
+
+	case patukek_obj.Float:
+		return vm.push(-o)
+
+	default:
+		return vm.errorf("unsupported operator '-' for type %v", o.Type())
+	}
+}
+
 func (vm *VM) execSub() error {
 	var (
 		right = patukek_obj.Unwrap(vm.pop())
@@ -150,6 +314,11 @@ func (vm *VM) execSub() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(l - r)
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.Float(l - r))
+
 	default:
 		return vm.errorf("unsupported operator '-' for types %v and %v", left.Type(), right.Type())
 	}
@@ -167,6 +336,11 @@ func (vm *VM) execMul() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(l * r)
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.Float(l * r))
+
 	default:
 		return vm.errorf("unsupported operator '*' for types %v and %v", left.Type(), right.Type())
 	}
@@ -178,13 +352,24 @@ func (vm *VM) execDiv() error {
 		left  = patukek_obj.Unwrap(vm.pop())
 	)
 
-	if !patukek_obj.AssertTypes(left, patukek_obj.IntType) || !patukek_obj.AssertTypes(right, patukek_obj.IntType) {
-		return fmt.Errorf("unsupported operator '/' for types %v and %v", left.Type(), right.Type())
-	}
+	switch {
+	case patukek_obj.AssertTypes(left, patukek_obj.IntType) && patukek_obj.AssertTypes(right, patukek_obj.IntType):
+		l := left.(patukek_obj.Integer)
+		r := right.(patukek_obj.Integer)
 
-	l := left.(patukek_obj.Integer)
-	r := right.(patukek_obj.Integer)
-	return vm.push(l / r)
+		if r == 0 {
+			return vm.errorf("can't divide by 0")
+		}
+		return vm.push(l / r)
+
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.Float(l / r))
+
+	default:
+		return vm.errorf("unsupported operator '/' for types %v and %v", left.Type(), right.Type())
+	}
 }
 
 func (vm *VM) execMod() error {
@@ -193,17 +378,24 @@ func (vm *VM) execMod() error {
 		left  = patukek_obj.Unwrap(vm.pop())
 	)
 
-	if !patukek_obj.AssertTypes(left, patukek_obj.IntType) || !patukek_obj.AssertTypes(right, patukek_obj.IntType) {
-		return fmt.Errorf("unsupported operator '%%' for types %v and %v", left.Type(), right.Type())
-	}
+	switch {
+	case patukek_obj.AssertTypes(left, patukek_obj.IntType) && patukek_obj.AssertTypes(right, patukek_obj.IntType):
+		l := left.(patukek_obj.Integer)
+		r := right.(patukek_obj.Integer)
+
+		if r == 0 {
+			return vm.errorf("can't divide by 0")
+		}
+		return vm.push(l % r)
 
-	l := left.(patukek_obj.Integer)
-	r := right.(patukek_obj.Integer)
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.Float(math.Mod(l, r)))
 
-	if r == 0 {
-		return vm.errorf("can't divide by 0")
+	default:
+		return vm.errorf("unsupported operator '%%' for types %v and %v", left.Type(), right.Type())
 	}
-	return vm.push(l % r)
 }
 
 func (vm *VM) execEqual() error {
@@ -226,8 +418,13 @@ func (vm *VM) execEqual() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(patukek_obj.ParseBool(l == r))
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.ParseBool(l == r))
+
 	default:
-		return vm.push(False)
+		return vm.push(patukek_obj.ParseBool(patukek_obj.Equal(left, right)))
 	}
 }
 
@@ -251,8 +448,13 @@ func (vm *VM) execNotEqual() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(patukek_obj.ParseBool(l != r))
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.ParseBool(l != r))
+
 	default:
-		return vm.push(True)
+		return vm.push(patukek_obj.ParseBool(!patukek_obj.Equal(left, right)))
 	}
 }
 
@@ -286,13 +488,22 @@ func (vm *VM) execGreaterThan() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(patukek_obj.ParseBool(l > r))
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.ParseBool(l > r))
+
 	case patukek_obj.AssertTypes(left, patukek_obj.StringType) && patukek_obj.AssertTypes(right, patukek_obj.StringType):
 		l := left.(patukek_obj.String)
 		r := right.(patukek_obj.String)
 		return vm.push(patukek_obj.ParseBool(l > r))
 
 	default:
-		return vm.errorf("unsupported operator '>' for types %v and %v", left.Type(), right.Type())
+		c, err := patukek_obj.Compare(left, right)
+		if err != nil {
+			return vm.errorf("unsupported operator '>' for types %v and %v", left.Type(), right.Type())
+		}
+		return vm.push(patukek_obj.ParseBool(c > 0))
 	}
 }
 
@@ -308,16 +519,62 @@ func (vm *VM) execGreaterThanEqual() error {
 		r := right.(patukek_obj.Integer)
 		return vm.push(patukek_obj.ParseBool(l >= r))
 
+	case isFloatOp(left, right):
+		l, _ := patukek_obj.ToFloat(left)
+		r, _ := patukek_obj.ToFloat(right)
+		return vm.push(patukek_obj.ParseBool(l >= r))
+
 	case patukek_obj.AssertTypes(left, patukek_obj.StringType) && patukek_obj.AssertTypes(right, patukek_obj.StringType):
 		l := left.(patukek_obj.String)
 		r := right.(patukek_obj.String)
 		return vm.push(patukek_obj.ParseBool(l >= r))
 
 	default:
-		return vm.errorf("unsupported operator '>=' for types %v and %v", left.Type(), right.Type())
+		c, err := patukek_obj.Compare(left, right)
+		if err != nil {
+			return vm.errorf("unsupported operator '>=' for types %v and %v", left.Type(), right.Type())
+		}
+		return vm.push(patukek_obj.ParseBool(c >= 0))
 	}
 }
 
+func (vm *VM) execGetAttr(name string) error {
+	obj := patukek_obj.Unwrap(vm.pop())
+
+	m, ok := obj.(patukek_obj.MapGetSetter)
+	if !ok {
+		return vm.errorf("type %v has no members", obj.Type())
+	}
+
+	o, ok := m.Get(name)
+	if !ok {
+		if ms, ok := obj.(interface{ Members() []string }); ok {
+			if s, ok := patukek_suggest.Closest(name, ms.Members()); ok {
+				return vm.errorf("%v has no member %s, did you mean %s?", obj, name, s)
+			}
+		}
+		return vm.errorf("%v has no member %s", obj, name)
+	}
+	return vm.push(o)
+}
+
+func (vm *VM) execSetAttr(name string) error {
+	var (
+		val = patukek_obj.Unwrap(vm.pop())
+		obj = patukek_obj.Unwrap(vm.pop())
+	)
+
+	m, ok := obj.(patukek_obj.MapGetSetter)
+	if !ok {
+		return vm.errorf("type %v has no members", obj.Type())
+	}
+
+	if e, ok := m.Set(name, val).(patukek_obj.Error); ok {
+		return vm.errorf("%s", e.Val())
+	}
+	return vm.push(val)
+}
+
 func (vm *VM) execReturnValue() error {
 	retVal := patukek_obj.Unwrap(vm.pop())
 	frame := vm.popFrame()
@@ -326,6 +583,57 @@ func (vm *VM) execReturnValue() error {
 	return vm.push(retVal)
 }
 
+func (vm *VM) execReturn() error {
+	frame := vm.popFrame()
+	vm.sp = frame.basePointer - 1
+
+	return vm.push(Null)
+}
+
+func (vm *VM) Call(fn patukek_obj.Object, args ...patukek_obj.Object) (patukek_obj.Object, error) {
+	switch f := patukek_obj.Unwrap(fn).(type) {
+	case patukek_obj.Builtin:
+		res := f(vm, args...)
+		if e, ok := res.(*patukek_obj.RuntimeError); ok {
+			return nil, e.Err
+		}
+		return res, nil
+
+	case *patukek_obj.Closure:
+		var (
+			sp    = vm.sp
+			depth = vm.frameIndex
+		)
+
+		if err := vm.push(f); err != nil {
+			return nil, err
+		}
+		for _, a := range args {
+			if err := vm.push(a); err != nil {
+				vm.sp = sp
+				return nil, err
+			}
+		}
+
+		if err := vm.callClosure(f, len(args)); err != nil {
+			vm.sp = sp
+			return nil, err
+		}
+		if err := vm.run(depth); err != nil {
+			vm.frameIndex = depth
+			vm.sp = sp
+			return nil, err
+		}
+
+		ret := vm.pop()
+		vm.sp = sp
+		return ret, nil
+
+	default:
+		return nil, vm.errorf("calling non-function")
+	}
+}
+
 func (vm *VM) call(o patukek_obj.Object, numArgs int) error {
 	switch fn := patukek_obj.Unwrap(o).(type) {
 	case *patukek_obj.Closure:
@@ -356,22 +664,55 @@ func (vm *VM) callClosure(cl *patukek_obj.Closure, nargs int) error {
 	}
 
 	frame := NewFrame(cl, vm.sp-nargs)
-	vm.pushFrame(frame)
+	if err := vm.pushFrame(frame); err != nil {
+		return err
+	}
 	vm.sp = frame.basePointer + cl.Fn.NumLocals
 	return nil
 }
 
 func (vm *VM) callBuiltin(fn patukek_obj.Builtin, nargs int) error {
 	args := vm.stack[vm.sp-nargs : vm.sp]
-	res := fn(args...)
+	res := fn(vm, args...)
 	vm.sp = vm.sp - nargs - 1
 
+	if vm.halted != nil {
+		return vm.halted
+	}
+	if e, ok := res.(*patukek_obj.RuntimeError); ok {
+		return e.Err
+	}
+	if f, ok := res.(*patukek_obj.Failure); ok {
+		return vm.errorf("%s", f.Msg)
+	}
+	if err := vm.checkSize(res); err != nil {
+		return err
+	}
+
 	if res == nil {
 		return vm.push(Null)
 	}
 	return vm.push(res)
 }
 
+func (vm *VM) interpolate(constIdx, n int) error {
+	var (
+		format = vm.Consts[constIdx].String()
+		values = make([]any, n)
+	)
+
+	for i := 0; i < n; i++ {
+		values[i] = patukek_obj.Unwrap(vm.stack[vm.sp-n+i])
+	}
+	vm.sp = vm.sp - n
+
+	s := patukek_obj.NewString(fmt.Sprintf(format, values...))
+	if err := vm.checkSize(s); err != nil {
+		return err
+	}
+	return vm.push(s)
+}
+
 func (vm *VM) pushClosure(constIdx, numFree int) error {
 	constant := vm.Consts[constIdx]
 	fn, ok := constant.(*patukek_obj.CompiledFunction)
@@ -387,7 +728,40 @@ func (vm *VM) pushClosure(constIdx, numFree int) error {
 	return vm.push(&patukek_obj.Closure{Fn: fn, Free: free})
 }
 
-func (vm *VM) Run() (err error) {
+func (vm *VM) Run(ctx context.Context) error {
+	defer vm.start(ctx)()
+
+	if err := vm.checkLimits(); err != nil {
+		return err
+	}
+	return vm.run(0)
+}
+
+func (vm *VM) CallContext(ctx context.Context, fn patukek_obj.Object, args ...patukek_obj.Object) (patukek_obj.Object, error) {
+	defer vm.start(ctx)()
+
+	if err := vm.checkLimits(); err != nil {
+		return nil, err
+	}
+	return vm.Call(fn, args...)
+}
+
+func (vm *VM) start(ctx context.Context) func() {
+	var cancel context.CancelFunc = func() {}
+
+	if vm.timeout > 0 {
+		ctx, cancel = context.WithTimeoutCause(ctx, vm.timeout, errTimeout)
+	}
+
+	vm.ctx = ctx
+	vm.steps, vm.halted = 0, nil
+	return func() {
+		cancel()
+		vm.ctx = context.Background()
+	}
+}
+
+func (vm *VM) run(depth int) (err error) {
 	var (
 		ip  int
 		ins patukek_code.Instructions
@@ -396,11 +770,21 @@ func (vm *VM) Run() (err error) {
 
 	defer func() {
 		if e := recover(); e != nil {
-			err = e.(error)
+			if perr, ok := e.(error); ok {
+				err = perr
+			} else {
+				err = fmt.Errorf("%v", e)
+			}
 		}
 	}()
 
-	for vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 && err == nil {
+	for vm.frameIndex > depth && vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 && err == nil {
+		if vm.steps++; vm.steps >= vm.nextCheck {
+			if err = vm.checkLimits(); err != nil {
+				break
+			}
+		}
+
 		vm.currentFrame().ip++
 
 		ip = vm.currentFrame().ip
@@ -443,13 +827,30 @@ func (vm *VM) Run() (err error) {
 			frame := vm.currentFrame()
 			err = vm.push(vm.stack[frame.basePointer+int(localIndex)])
 
+		case patukek_code.OpSetLocal:
+			localIndex := patukek_code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+
+			frame := vm.currentFrame()
+			vm.stack[frame.basePointer+int(localIndex)] = vm.peek()
+
+		case patukek_code.OpGetFree:
+			freeIndex := patukek_code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip += 1
+			err = vm.push(vm.currentFrame().cl.Free[freeIndex])
+
+		case patukek_code.OpCurrentClosure:
+			err = vm.push(vm.currentFrame().cl)
+
 		case patukek_code.OpList:
 			nElements := int(patukek_code.ReadUint16(ins[ip+1:]))
 			vm.currentFrame().ip += 2
 
 			list := vm.buildList(vm.sp-nElements, vm.sp)
 			vm.sp = vm.sp - nElements
-			err = vm.push(list)
+			if err = vm.checkSize(list); err == nil {
+				err = vm.push(list)
+			}
 
 		case patukek_code.OpCall:
 			numArgs := patukek_code.ReadUint8(ins[ip+1:])
@@ -459,8 +860,8 @@ func (vm *VM) Run() (err error) {
 		case patukek_code.OpGetBuiltin:
 			idx := patukek_code.ReadUint8(ins[ip+1:])
 			vm.currentFrame().ip += 1
-			def := patukek_obj.Builtins[idx]
-			err = vm.push(def.Builtin)
+			def := vm.Builtins[idx]
+			err = vm.push(def.Object())
 
 		case patukek_code.OpClosure:
 			constIdx := patukek_code.ReadUint16(ins[ip+1:])
@@ -468,9 +869,28 @@ func (vm *VM) Run() (err error) {
 			vm.currentFrame().ip += 3
 			err = vm.pushClosure(int(constIdx), int(numFree))
 
+		case patukek_code.OpGetAttr:
+			constIdx := patukek_code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			err = vm.execGetAttr(vm.Consts[constIdx].String())
+
+		case patukek_code.OpSetAttr:
+			constIdx := patukek_code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			err = vm.execSetAttr(vm.Consts[constIdx].String())
+
+		case patukek_code.OpInterpolate:
+			constIdx := patukek_code.ReadUint16(ins[ip+1:])
+			n := patukek_code.ReadUint8(ins[ip+3:])
+			vm.currentFrame().ip += 3
+			err = vm.interpolate(int(constIdx), int(n))
+
 		case patukek_code.OpReturnValue:
 			err = vm.execReturnValue()
 
+		case patukek_code.OpReturn:
+			err = vm.execReturn()
+
 		case patukek_code.OpNull:
 			err = vm.push(Null)
 
@@ -480,6 +900,9 @@ func (vm *VM) Run() (err error) {
 		case patukek_code.OpSub:
 			err = vm.execSub()
 
+		case patukek_code.OpMinus:
+			err = vm.execMinus()
+
 		case patukek_code.OpMul:
 			err = vm.execMul()
 
@@ -516,7 +939,7 @@ func (vm *VM) Run() (err error) {
 }
 
 func (vm *VM) push(o patukek_obj.Object) error {
-	if vm.sp >= StackSize {
+	if vm.sp >= len(vm.stack) {
 		return vm.errorf("stack overflow")
 	}
 
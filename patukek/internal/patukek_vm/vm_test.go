@@ -0,0 +1,167 @@
+package patukek_vm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_parser"
+)
+
+// run runs src and returns what it prints.
+func run(t *testing.T, src string) string {
+	t.Helper()
+
+	out, err := runErr(t, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return out
+}
+
+// runErr runs src and returns what it prints and the error it stops with.
+func runErr(t *testing.T, src string) (string, error) {
+	t.Helper()
+
+	var out strings.Builder
+	err := newVM(t, src, Config{Stdout: &out}).Run(context.Background())
+	return out.String(), err
+}
+
+// newVM compiles src and returns a VM that runs it with cfg, reading
+// nothing and discarding its output unless cfg says otherwise.
+func newVM(t *testing.T, src string, cfg Config) *VM {
+	t.Helper()
+
+	tree, errs := patukek_parser.Parse("test.ptk", src)
+	if len(errs) > 0 {
+		t.Fatalf("parse: %v", errs)
+	}
+
+	c := patukek_compiler.New()
+	c.SetFileInfo("test.ptk", src)
+	if err := c.Compile(tree); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	if cfg.Stdin == nil {
+		cfg.Stdin = strings.NewReader("")
+	}
+	if cfg.Stdout == nil {
+		cfg.Stdout = io.Discard
+	}
+	if cfg.Stderr == nil {
+		cfg.Stderr = io.Discard
+	}
+	return NewWithState("test.ptk", c.Bytecode(), NewState(), cfg)
+}
+
+// TestFunctions covers instructions that the VM did not execute before
+// functions could be called from Go: OpSetLocal, OpGetFree,
+// OpCurrentClosure and OpReturn.
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "local assignment",
+			src:  "f = patukek() {\n\tx = 1\n\tx = x + 1\n\tx\n}\nprintln(f())",
+			want: "2\n",
+		},
+		{
+			name: "free variable",
+			src:  "adder = patukek(n) { patukek(x) { x + n } }\nprintln(adder(2)(3))",
+			want: "5\n",
+		},
+		{
+			name: "local recursion",
+			src:  "outer = patukek() {\n\tfact = patukek(n) {\n\t\tif n < 2 {\n\t\t\treturn 1\n\t\t}\n\t\tn * fact(n - 1)\n\t}\n\tfact(5)\n}\nprintln(outer())",
+			want: "120\n",
+		},
+		{
+			name: "empty body",
+			src:  "f = patukek() { }\nprintln(f())\nprintln(1)",
+			want: "null\n1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(t, tt.src); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "nested calls",
+			src:  "g = patukek() { assert(1 == 2) }\nf = patukek() { g() }\nf()",
+			want: "called from:\n    test.ptk:2: f = patukek() { g() }\n    test.ptk:3: f()",
+		},
+		{
+			name: "recursion",
+			src:  "f = patukek(n) {\n\tf(n + 1)\n}\nf(0)",
+			want: "called from:\n    test.ptk:2: f(n + 1)\n    ... 1021 more\n    test.ptk:4: f(0)",
+		},
+		{
+			name: "mutual recursion",
+			src:  "f = patukek(h, n) { h(f, n + 1) }\ng = patukek(h, n) { h(g, n) }\nf(g, 0)",
+			want: "\n    test.ptk:2: g = patukek(h, n) { h(g, n) }\n    ... 662 more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runErr(t, tt.src)
+			if err == nil {
+				t.Fatal("succeeded, want an error")
+			}
+			if got := err.Error(); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("got %q, want it to end with %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain", `println("100%")`, "100%\n"},
+		{"percent", "x = 5\nprintln(\"{x}%\")", "5%\n"},
+		{"escaped percent", "x = 5\nprintln(\"50%% {x}\")\nprintln(\"50%%\")", "50%% 5\n50%%\n"},
+		{"verbs", "x = \"%d\"\nprintln(\"{x} %s\")", "%d %s\n"},
+		{"braces", "x = 5\nprintln(\"{{x}} = {x}\")", "{x} = 5\n"},
+		{"empty", `println("")`, "\n"},
+		{"expressions", "x = 5\nprintln(\"{x + 1}{x * 2}\")", "610\n"},
+		{"unicode", "name = \"мир\"\nprintln(\"привет, {upper(name)}! {len(name)}\")", "привет, МИР! 6\n"},
+		{"in function", "f = patukek(a, b) { \"{a}/{b}\" }\nprintln(f(1, \"ё\"))", "1/ё\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(t, tt.src); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, src := range []string{`println("a}")`, `println("{a")`, `println("{)}")`} {
+		if _, errs := patukek_parser.Parse("test.ptk", src); len(errs) == 0 {
+			t.Errorf("Parse(%q) succeeded, want an error", src)
+		}
+	}
+}
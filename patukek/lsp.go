@@ -0,0 +1,23 @@
+package main
+
+import (
+	"patukek/internal/patukek_lsp"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func lspCmd(args []string) int {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek lsp")
+		fmt.Fprintln(fs.Output(), "Runs a language server speaking LSP over stdin and stdout.")
+	}
+	_ = fs.Parse(args)
+
+	if err := patukek_lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
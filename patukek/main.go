@@ -1,14 +1,26 @@
 package main
 
 import (
+	"patukek/internal/patukek_build"
 	"patukek/internal/patukek_compiler"
-	"patukek/internal/patukek_parser"
 	"patukek/internal/patukek_vm"
+	"context"
 	"flag"
 	"fmt"
 	"os"
 )
 
+var commands = map[string]func(args []string) int{
+	"run":     runCmd,
+	"fmt":     fmtCmd,
+	"lsp":     lspCmd,
+	"test":    testCmd,
+	"doctest": doctestCmd,
+	"ast":     astCmd,
+	"vet":     vetCmd,
+	"check":   checkCmd,
+}
+
 func readFile(fname string) []byte {
 	b, err := os.ReadFile(fname)
 	if err != nil {
@@ -18,17 +30,8 @@ func readFile(fname string) []byte {
 	return b
 }
 
-func compile(path string) (bc *patukek_compiler.Bytecode, err error) {
-	input := string(readFile(path))
-	res, _ := patukek_parser.Parse(path, input)
-
-	c := patukek_compiler.New()
-	c.SetFileInfo(path, input)
-	if err = c.Compile(res); err != nil {
-		return
-	}
-
-	return c.Bytecode(), nil
+func compile(path string) (*patukek_compiler.Bytecode, error) {
+	return patukek_build.Build(path, string(readFile(path)))
 }
 
 func execFileVM(f string) (err error) {
@@ -39,18 +42,39 @@ func execFileVM(f string) (err error) {
 		return
 	}
 	tvm := patukek_vm.New(f, bytecode)
-	if err = tvm.Run(); err != nil {
+	if err = tvm.Run(context.Background()); err != nil {
 		fmt.Println(err)
 		return
 	}
 	return
 }
 
+func runCmd(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek run file.ptk")
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if err := execFileVM(fs.Arg(0)); err != nil {
+		return 1
+	}
+	return 0
+}
+
 func main() {
 	flag.Parse()
 	if flag.NArg() == 0 {
 		fmt.Println("See instructions to run patukek programs in REPORT.md")
-	} else {
-		_ = execFileVM(flag.Arg(0))
+		return
+	}
+
+	if cmd, ok := commands[flag.Arg(0)]; ok {
+		os.Exit(cmd(flag.Args()[1:]))
 	}
-}
\ No newline at end of file
+	_ = execFileVM(flag.Arg(0))
+}
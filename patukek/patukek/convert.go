@@ -0,0 +1,17 @@
+package patukek
+
+import "patukek/internal/patukek_obj"
+
+// ToObject converts a Go value to its patukek representation. Integers,
+// strings, booleans, slices and arrays are converted by value, structs and
+// pointers are wrapped as native structs and nil becomes null.
+func ToObject(v any) Object {
+	return patukek_obj.ToObject(v)
+}
+
+// FromObject converts a patukek value to a Go value: integers become int64,
+// lists become []any and null becomes nil. Values without a Go counterpart
+// are returned as they are.
+func FromObject(o Object) any {
+	return patukek_obj.ToAny(o)
+}
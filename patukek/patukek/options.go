@@ -0,0 +1,38 @@
+package patukek
+
+import (
+	"io"
+	"time"
+)
+
+// Options configures a VM created with NewVM. The zero value is valid and
+// runs programs with the process standard streams and no limits.
+type Options struct {
+	// Stdin is read by the input builtins. It is buffered once per VM, so
+	// input not consumed by one run is seen by the next. Defaults to os.Stdin.
+	Stdin io.Reader
+	// Stdout receives the output of println and input prompts. Defaults to
+	// os.Stdout.
+	Stdout io.Writer
+	// Stderr receives the output of eprintln. Defaults to os.Stderr.
+	Stderr io.Writer
+	// StackSize is the number of value slots on the VM stack.
+	StackSize int
+	// MaxFrames is the maximum call depth.
+	MaxFrames int
+	// MaxInstructions stops a run after the given number of VM
+	// instructions. Zero means no limit.
+	MaxInstructions int64
+	// MaxAllocSize is the maximum length of a list or string created by a
+	// program. Zero means no limit.
+	MaxAllocSize int
+	// Timeout stops a run after the given wall-clock time. Zero means no
+	// limit.
+	Timeout time.Duration
+	// Seed initializes the generator used by random and randint, so that
+	// runs with the same seed produce the same numbers.
+	Seed uint64
+	// Builtins are made available to programs in addition to the default
+	// builtins, replacing the defaults that have the same name.
+	Builtins map[string]Builtin
+}
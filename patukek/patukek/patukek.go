@@ -0,0 +1,205 @@
+// Package patukek embeds the patukek compiler and virtual machine into Go
+// programs.
+package patukek
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"patukek/internal/patukek_ast"
+	"patukek/internal/patukek_build"
+	"patukek/internal/patukek_compiler"
+	"patukek/internal/patukek_obj"
+	"patukek/internal/patukek_parser"
+	"patukek/internal/patukek_types"
+	"patukek/internal/patukek_vm"
+)
+
+type (
+	Object     = patukek_obj.Object
+	Builtin    = patukek_obj.Builtin
+	Context    = patukek_obj.Context
+	LimitError = patukek_vm.LimitError
+	LimitKind  = patukek_vm.LimitKind
+)
+
+const (
+	InstructionLimit = patukek_vm.InstructionLimit
+	TimeLimit        = patukek_vm.TimeLimit
+	AllocLimit       = patukek_vm.AllocLimit
+	Canceled         = patukek_vm.Canceled
+)
+
+var Null = patukek_obj.NullObj
+
+// Program is a parsed patukek source file. Names are resolved when the
+// program is run, against the globals and builtins of the VM running it.
+type Program struct {
+	name string
+	src  string
+	tree patukek_ast.Node
+}
+
+// Compile parses src. The name is used in error messages. Types are
+// checked as patukek run does when the program is run, against the globals
+// and builtins of the VM.
+func Compile(name, src string) (*Program, error) {
+	tree, errs := patukek_parser.Parse(name, src)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &Program{name: name, src: src, tree: tree}, nil
+}
+
+// VM runs programs. Globals defined by one program stay visible to the
+// programs and expressions run after it on the same VM. A VM must not be
+// used from several goroutines at once.
+type VM struct {
+	state *patukek_vm.State
+	opts  Options
+	m     *patukek_vm.VM
+}
+
+func NewVM(opts Options) (*VM, error) {
+	if opts.Stdin == nil {
+		opts.Stdin = os.Stdin
+	}
+	opts.Stdin = bufio.NewReader(opts.Stdin)
+
+	vm := &VM{state: patukek_vm.NewState(), opts: opts}
+	vm.state.Seed(opts.Seed)
+
+	names := make([]string, 0, len(opts.Builtins))
+	for n := range opts.Builtins {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		if err := vm.state.DefineBuiltin(n, opts.Builtins[n]); err != nil {
+			return nil, err
+		}
+	}
+	return vm, nil
+}
+
+// RegisterFunc makes the Go function fn callable from programs run on this
+// VM under name. Arguments are converted with the same rules as
+// FromObject, results with ToObject; a trailing error result is returned to
+// the program as an error value when it is not nil.
+func (vm *VM) RegisterFunc(name string, fn any) error {
+	return vm.state.RegisterFunc(name, fn)
+}
+
+// RegisterBuiltin makes fn callable from programs run on this VM under name.
+func (vm *VM) RegisterBuiltin(name string, fn Builtin) error {
+	return vm.state.DefineBuiltin(name, fn)
+}
+
+// Run checks and compiles p against the VM globals and builtins, and
+// executes it. Execution stops with a *LimitError when ctx is done or a
+// limit set in Options is reached.
+func (vm *VM) Run(ctx context.Context, p *Program) error {
+	_, err := vm.exec(ctx, p)
+	return err
+}
+
+// Eval runs expr and returns the value of its last expression.
+func (vm *VM) Eval(ctx context.Context, expr string) (Object, error) {
+	p, err := Compile("<eval>", expr)
+	if err != nil {
+		return nil, err
+	}
+	return vm.exec(ctx, p)
+}
+
+// Get returns the value of the global variable name.
+func (vm *VM) Get(name string) (Object, bool) {
+	s, ok := vm.state.Symbols.Resolve(name)
+	if !ok || s.Scope != patukek_compiler.GlobalScope {
+		return nil, false
+	}
+
+	if o := vm.state.Globals[s.Index]; o != nil {
+		return o, true
+	}
+	return Null, true
+}
+
+// Set assigns v, converted with ToObject, to the global variable name,
+// defining it if needed. Like an assignment in a program, it hides the
+// builtin of the same name.
+func (vm *VM) Set(name string, v any) error {
+	if s, ok := vm.state.Symbols.Resolve(name); ok && s.Scope != patukek_compiler.GlobalScope && s.Scope != patukek_compiler.BuiltinScope {
+		return fmt.Errorf("cannot assign to %s", name)
+	}
+
+	o := ToObject(v)
+	if e, ok := o.(patukek_obj.Error); ok {
+		return errors.New(e.Val())
+	}
+
+	s := vm.state.Symbols.Define(name)
+	vm.state.Globals[s.Index] = o
+	return nil
+}
+
+// Call calls the patukek function fn, usually obtained with Get, with args
+// converted by ToObject and returns its result.
+func (vm *VM) Call(ctx context.Context, fn Object, args ...any) (Object, error) {
+	if vm.m == nil {
+		vm.m = vm.newMachine("", &patukek_compiler.Bytecode{Constants: vm.state.Consts})
+	}
+
+	a := make([]Object, len(args))
+	for i, v := range args {
+		a[i] = ToObject(v)
+	}
+	return vm.m.CallContext(ctx, fn, a...)
+}
+
+func (vm *VM) exec(ctx context.Context, p *Program) (Object, error) {
+	if errs := patukek_types.CheckEnv(p.name, p.src, p.tree, vm.env()); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	c := patukek_compiler.NewWithState(vm.state.Symbols, &vm.state.Consts)
+	bc, err := patukek_build.Compile(c, p.name, p.src, p.tree)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.m = vm.newMachine(p.name, bc)
+	if err := vm.m.Run(ctx); err != nil {
+		return nil, err
+	}
+	return vm.m.LastPopped(), nil
+}
+
+// env returns the names that programs run on vm see besides their own.
+func (vm *VM) env() patukek_types.Env {
+	env := patukek_types.Env{Builtins: vm.state.Builtins}
+	for n, s := range vm.state.Symbols.Store {
+		if s.Scope == patukek_compiler.GlobalScope {
+			env.Globals = append(env.Globals, n)
+		}
+	}
+	return env
+}
+
+func (vm *VM) newMachine(name string, bc *patukek_compiler.Bytecode) *patukek_vm.VM {
+	return patukek_vm.NewWithState(name, bc, vm.state, patukek_vm.Config{
+		Stdin:           vm.opts.Stdin,
+		Stdout:          vm.opts.Stdout,
+		Stderr:          vm.opts.Stderr,
+		StackSize:       vm.opts.StackSize,
+		MaxFrames:       vm.opts.MaxFrames,
+		MaxInstructions: vm.opts.MaxInstructions,
+		MaxAllocSize:    vm.opts.MaxAllocSize,
+		Timeout:         vm.opts.Timeout,
+	})
+}
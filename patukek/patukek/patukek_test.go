@@ -0,0 +1,389 @@
+package patukek
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"patukek/internal/patukek_obj"
+)
+
+// newVM returns a VM that reads in and writes to the returned builder.
+func newVM(t *testing.T, in string, opts Options) (*VM, *strings.Builder) {
+	t.Helper()
+
+	var out strings.Builder
+	opts.Stdin = strings.NewReader(in)
+	opts.Stdout = &out
+
+	vm, err := NewVM(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm, &out
+}
+
+func runSrc(t *testing.T, vm *VM, src string) {
+	t.Helper()
+
+	p, err := Compile("test.ptk", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Run(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	vm, out := newVM(t, "world\n", Options{})
+	runSrc(t, vm, "name = readline()\nprintln(\"hello \" + name)")
+
+	if got := out.String(); got != "hello world\n" {
+		t.Errorf("got %q, want %q", got, "hello world\n")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"x = (1", "expected"},
+		{`x: int = "one"`, "cannot use string as int in assignment to x"},
+		{"f = patukek(n: int) { n }\nf(\"a\")", "cannot use string as int in argument 1 to f"},
+		{"len(1, 2)", "len takes 1 argument, got 2"},
+	}
+
+	for _, tt := range tests {
+		vm, _ := newVM(t, "", Options{})
+		_, err := vm.Eval(context.Background(), tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Eval(%q) = %v, want an error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// TestShadowedBuiltins checks that programs are type-checked against the
+// names of their VM: globals and host builtins hide the builtins of the
+// same name.
+func TestShadowedBuiltins(t *testing.T) {
+	vm, _ := newVM(t, "", Options{
+		Builtins: map[string]Builtin{
+			"len": func(ctx Context, args ...Object) Object {
+				return ToObject(int64(len(args)))
+			},
+		},
+	})
+	runSrc(t, vm, "max = 5")
+	if err := vm.Set("split", "a,b"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		src  string
+		want any
+	}{
+		{"max + 1", int64(6)},
+		{`split + ",c"`, "a,b,c"},
+		{"len(1, 2)", int64(2)},
+	}
+
+	for _, tt := range tests {
+		v, err := vm.Eval(context.Background(), tt.src)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.src, err)
+		} else if got := FromObject(v); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	vm, _ := newVM(t, "", Options{})
+	runSrc(t, vm, "x = 40")
+
+	if err := vm.Set("y", 2); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vm.Eval(context.Background(), "x + y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FromObject(v); got != int64(42) {
+		t.Errorf("x + y = %v, want 42", got)
+	}
+
+	if _, ok := vm.Get("missing"); ok {
+		t.Error("Get found an undefined variable")
+	}
+}
+
+func TestCall(t *testing.T) {
+	vm, _ := newVM(t, "", Options{})
+	runSrc(t, vm, "add = patukek(a, b) { a + b }")
+
+	fn, ok := vm.Get("add")
+	if !ok {
+		t.Fatal("add is not defined")
+	}
+	v, err := vm.Call(context.Background(), fn, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FromObject(v); got != int64(3) {
+		t.Errorf("add(1, 2) = %v, want 3", got)
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	vm, out := newVM(t, "", Options{
+		Builtins: map[string]Builtin{
+			"twice": func(ctx Context, args ...Object) Object {
+				return ToObject(FromObject(args[0]).(int64) * 2)
+			},
+		},
+	})
+	if err := vm.RegisterFunc("shout", strings.ToUpper); err != nil {
+		t.Fatal(err)
+	}
+	runSrc(t, vm, "println(twice(21), shout(\"hi\"))")
+
+	if got := out.String(); got != "42 HI\n" {
+		t.Errorf("got %q, want %q", got, "42 HI\n")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	vm, _ := newVM(t, "", Options{MaxInstructions: 1000})
+
+	p, err := Compile("test.ptk", "loop = patukek(n) { loop(n + 1) }\nloop(0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lerr *LimitError
+	if err := vm.Run(context.Background(), p); !errors.As(err, &lerr) || lerr.Kind != InstructionLimit {
+		t.Errorf("got %v, want an instruction limit error", err)
+	}
+}
+
+// TestCallbackError checks that a runtime error in a patukek function
+// called by a Go function stops the program, as it does in map.
+func TestCallbackError(t *testing.T) {
+	apply := func(f func(int64) int64, x int64) int64 { return f(x) }
+
+	for _, call := range []string{"apply(patukek(x) { x / 0 }, 1)", "map([1], patukek(x) { x / 0 })"} {
+		vm, out := newVM(t, "", Options{})
+		if err := vm.RegisterFunc("apply", apply); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := Compile("test.ptk", call+"\nprintln(\"after\")")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = vm.Run(context.Background(), p)
+		if err == nil || !strings.Contains(err.Error(), "divide by 0") {
+			t.Errorf("%s: got %v, want a division by zero error", call, err)
+		}
+		if strings.Contains(out.String(), "after") {
+			t.Errorf("%s: the program went on after the error: %q", call, out.String())
+		}
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	vm, _ := newVM(t, "", Options{})
+	funcs := map[string]any{
+		"u8":     func(b uint8) uint8 { return b },
+		"i8":     func(b int8) int8 { return b },
+		"halve":  func(n int) (int, error) { return n / 2, nil },
+		"fail":   func() error { return errors.New("failed") },
+		"concat": func(s ...string) string { return strings.Join(s, "") },
+	}
+	for name, fn := range funcs {
+		if err := vm.RegisterFunc(name, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		src  string
+		want any
+	}{
+		{"u8(255)", int64(255)},
+		{"i8(-128)", int64(-128)},
+		{"halve(9)", int64(4)},
+		{`concat("a", "b", "c")`, "abc"},
+	}
+	for _, tt := range tests {
+		v, err := vm.Eval(context.Background(), tt.src)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.src, err)
+		} else if got := FromObject(v); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+
+	errs := []struct {
+		src  string
+		want string
+	}{
+		{"u8(300)", "u8: 300 overflows uint8"},
+		{"u8(-1)", "u8: -1 overflows uint8"},
+		{"i8(128)", "i8: 128 overflows int8"},
+		{`halve("a")`, "halve: expected int but string provided"},
+		{"fail()", "fail: failed"},
+	}
+	for _, tt := range errs {
+		v, err := vm.Eval(context.Background(), tt.src)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.src, err)
+		} else if e, ok := v.(patukek_obj.Error); !ok || e.Val() != tt.want {
+			t.Errorf("Eval(%q) = %v, want the error %q", tt.src, v, tt.want)
+		}
+	}
+}
+
+func TestRegisterFuncResults(t *testing.T) {
+	vm, _ := newVM(t, "", Options{})
+
+	for _, fn := range []any{
+		func() {},
+		func() (int, string) { return 0, "" },
+		func() (int, int, error) { return 0, 0, nil },
+		func() (error, error) { return nil, nil },
+		"not a function",
+	} {
+		if err := vm.RegisterFunc("f", fn); err == nil {
+			t.Errorf("RegisterFunc accepted a %T", fn)
+		}
+	}
+}
+
+type point struct {
+	X, Y int64
+	name string
+}
+
+func (p *point) Move(dx, dy int64) {
+	p.X += dx
+	p.Y += dy
+}
+
+type segment struct {
+	From, To *point
+}
+
+// TestMembers checks the access to the fields and methods of Go structs
+// set as globals.
+func TestMembers(t *testing.T) {
+	p := &point{X: 1, Y: 2}
+
+	vm, _ := newVM(t, "", Options{})
+	for n, v := range map[string]any{
+		"p":   p,
+		"s":   &segment{From: p, To: &point{X: 10}},
+		"val": point{X: 3},
+		"n":   1,
+	} {
+		if err := vm.Set(n, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		src  string
+		want Object
+	}{
+		{"p.X", patukek_obj.Integer(1)},
+		{"s.To.X - s.From.Y", patukek_obj.Integer(8)},
+		{"val.X", patukek_obj.Integer(3)},
+		{"p.X = 5", patukek_obj.Integer(5)},
+		{"p.Move(1, 1)\np.X + p.Y", patukek_obj.Integer(9)},
+		{"s.From.Y = s.To.X\np.Y", patukek_obj.Integer(10)},
+	}
+	for _, tt := range tests {
+		got, err := vm.Eval(context.Background(), tt.src)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.src, err)
+		} else if !patukek_obj.Equal(got, tt.want) {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+	if *p != (point{X: 6, Y: 10}) {
+		t.Errorf("p = %+v after the assignments, want {X:6 Y:10}", *p)
+	}
+
+	errs := []struct {
+		src  string
+		want string
+	}{
+		{"p.Z", "has no member Z"},
+		{"p.Mov", "has no member Mov, did you mean Move?"},
+		{"p.name", "has no member name"},
+		{"p.Z = 1", "*patukek.point has no field Z"},
+		{"p.Xx = 1", "*patukek.point has no field Xx, did you mean X?"},
+		{`p.X = "a"`, "*patukek.point.X: expected int64 but string provided"},
+		{"val.X = 1", "cannot assign to field X of patukek.point"},
+		{"n.X", "type int has no members"},
+		{"n.X = 1", "type int has no members"},
+	}
+	for _, tt := range errs {
+		_, err := vm.Eval(context.Background(), tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Eval(%q) = %v, want an error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		src  string
+		want string
+	}{
+		{"readline", "a b\r\nc\n", "println(readline())\nprintln(readline())\nprintln(readline())", "a b\nc\nnull\n"},
+		{"readline without newline", "a\nb", "println(readline() + readline())\nprintln(eof())", "ab\ntrue\n"},
+		{"readline empty line", "\r\n\n", "println(len(readline()), len(readline()), eof())", "0 0 true\n"},
+		{"readline empty", "", "println(readline())", "null\n"},
+		{"readall", "a\r\nb\n", "println(len(readall()))\nprintln(len(readall()))", "5\n0\n"},
+		{"readall after readline", "a\nb\nc", "readline()\nprintln(readall())", "b\nc\n"},
+		{"lines", "a\r\n\r\nb", "println(lines())\nprintln(lines())", "[\"a\", \"\", \"b\"]\n[]\n"},
+		{"lines empty", "", "println(len(lines()))", "0\n"},
+		{"input", "  one two\r\n\nthree", "println(input(), input(), input(), input())", "one  three \n"},
+		{"eof", "a\n", "println(eof())\nreadline()\nprintln(eof())", "false\ntrue\n"},
+		{"eof empty", "", "println(eof())", "true\n"},
+		{
+			"each_line",
+			"a\r\nbb\n\nccc",
+			"n = patukek(l) { println(len(l), l) }\neach_line(n)\nprintln(eof())",
+			"1 a\n2 bb\n0 \n3 ccc\ntrue\n",
+		},
+		{
+			"each_line reads lazily",
+			"a\nb\nc\n",
+			"f = patukek(l) { println(l, readline()) }\neach_line(f)",
+			"a b\nc null\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm, out := newVM(t, tt.in, Options{})
+			runSrc(t, vm, tt.src)
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	vm, _ := newVM(t, "a\n", Options{})
+	if _, err := vm.Eval(context.Background(), "each_line(patukek(l) { l / 2 })"); err == nil {
+		t.Error("an error in the function of each_line did not stop the program")
+	}
+}
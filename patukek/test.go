@@ -0,0 +1,131 @@
+package main
+
+import (
+	"patukek/internal/patukek_tester"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// testReport is the outcome of patukek test. Failed counts the failed
+// tests and the files whose tests could not be run, listed in Errors.
+type testReport struct {
+	Tests   []patukek_tester.Result `json:"tests"`
+	Passed  int                     `json:"passed"`
+	Failed  int                     `json:"failed"`
+	Errors  []string                `json:"errors,omitempty"`
+	Elapsed time.Duration           `json:"elapsed_ns"`
+}
+
+func testCmd(args []string) int {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	run := fs.String("run", "", "run only the tests whose name matches the regular expression")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek test [-run regexp] [-json] [file_test.ptk | dir ...]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	var filter *regexp.Regexp
+	if *run != "" {
+		re, err := regexp.Compile(*run)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "patukek test: invalid -run:", err)
+			return 2
+		}
+		filter = re
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := patukek_tester.Discover(paths...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out := io.Writer(os.Stdout)
+	if *jsonOut {
+		out = io.Discard
+	}
+	report := runTests(out, files, filter)
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		fmt.Println(report.summary())
+	}
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runTests runs the tests of files matching filter and writes their
+// failures to w.
+func runTests(w io.Writer, files []string, filter *regexp.Regexp) testReport {
+	var (
+		report testReport
+		start  = time.Now()
+	)
+	for _, f := range files {
+		res, err := patukek_tester.RunFile(context.Background(), f, filter)
+		if err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, err.Error())
+			fmt.Fprintln(w, err)
+			continue
+		}
+
+		for _, r := range res {
+			if r.Passed {
+				report.Passed++
+			} else {
+				report.Failed++
+				printFailure(w, r)
+			}
+		}
+		report.Tests = append(report.Tests, res...)
+	}
+	report.Elapsed = time.Since(start)
+	return report
+}
+
+func (r testReport) summary() string {
+	status := "ok"
+	if r.Failed > 0 {
+		status = "FAIL"
+	}
+	return fmt.Sprintf("%s: %d passed, %d failed (%.3fs)", status, r.Passed, r.Failed, r.Elapsed.Seconds())
+}
+
+func printFailure(w io.Writer, r patukek_tester.Result) {
+	line := r.ErrorLine
+	if line == 0 {
+		line = r.Line
+	}
+
+	fmt.Fprintf(w, "--- FAIL: %s (%s:%d)\n", r.Name, r.File, line)
+	for _, l := range strings.Split(strings.TrimRight(r.Error, "\n"), "\n") {
+		fmt.Fprintf(w, "    %s\n", l)
+	}
+	if r.Output != "" {
+		fmt.Fprintln(w, "    output:")
+		for _, l := range strings.Split(strings.TrimRight(r.Output, "\n"), "\n") {
+			fmt.Fprintf(w, "        %s\n", l)
+		}
+	}
+}
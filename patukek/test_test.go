@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunTests checks that files whose tests cannot be run count as
+// failures, so that patukek test does not report them as 0 failed.
+func TestRunTests(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"ok_test.ptk":      "test_ok = patukek() { assert_eq(1, 1) }",
+		"syntax_test.ptk":  "test_a = patukek() {",
+		"types_test.ptk":   "x: int = \"a\"\ntest_b = patukek() { x }",
+		"failing_test.ptk": "test_fail = patukek() { assert_eq(1, 2) }",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		files          []string
+		passed, failed int
+		errors         int
+		summary        string
+	}{
+		{[]string{"ok_test.ptk"}, 1, 0, 0, "ok: 1 passed, 0 failed"},
+		{[]string{"syntax_test.ptk"}, 0, 1, 1, "FAIL: 0 passed, 1 failed"},
+		{[]string{"ok_test.ptk", "syntax_test.ptk", "types_test.ptk"}, 1, 2, 2, "FAIL: 1 passed, 2 failed"},
+		{[]string{"failing_test.ptk", "types_test.ptk"}, 0, 2, 1, "FAIL: 0 passed, 2 failed"},
+	}
+
+	for _, tt := range tests {
+		var paths []string
+		for _, f := range tt.files {
+			paths = append(paths, filepath.Join(dir, f))
+		}
+
+		var out strings.Builder
+		r := runTests(&out, paths, nil)
+		if r.Passed != tt.passed || r.Failed != tt.failed {
+			t.Errorf("%v: %d passed, %d failed, want %d and %d", tt.files, r.Passed, r.Failed, tt.passed, tt.failed)
+		}
+		if got := r.summary(); !strings.HasPrefix(got, tt.summary+" (") {
+			t.Errorf("%v: summary %q, want %q", tt.files, got, tt.summary)
+		}
+		if len(r.Errors) != tt.errors {
+			t.Errorf("%v: errors %q, want %d", tt.files, r.Errors, tt.errors)
+		}
+		for _, e := range r.Errors {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("%v: the error %q is not printed", tt.files, e)
+			}
+		}
+	}
+}
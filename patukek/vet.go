@@ -0,0 +1,72 @@
+package main
+
+import (
+	"patukek/internal/patukek_vet"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func vetCmd(args []string) int {
+	fs := flag.NewFlagSet("vet", flag.ExitOnError)
+	checks := fs.String("checks", "", "comma-separated `list` of checks to run instead of all of them")
+	disable := fs.String("disable", "", "comma-separated `list` of checks not to run")
+	jsonOut := fs.Bool("json", false, "print the diagnostics as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: patukek vet [-checks list] [-disable list] [-json] file.ptk ...")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nchecks:")
+		for _, c := range patukek_vet.Checks {
+			fmt.Fprintf(fs.Output(), "  %-15s %s\n", c.ID, c.Doc)
+		}
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	enabled, err := patukek_vet.Enabled(*checks, *disable)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "patukek vet: %v\n", err)
+		return 2
+	}
+
+	var (
+		ret   int
+		diags = []patukek_vet.Diagnostic{}
+	)
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ret = 1
+			continue
+		}
+
+		d, err := patukek_vet.Vet(path, string(src), enabled)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ret = 1
+			continue
+		}
+		diags = append(diags, d...)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(diags)
+	} else {
+		for _, d := range diags {
+			fmt.Println(d)
+		}
+	}
+
+	if len(diags) > 0 {
+		ret = 1
+	}
+	return ret
+}